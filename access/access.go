@@ -0,0 +1,226 @@
+// Package access keeps the owner of a contract and its role-based
+// access control in the state of the contract, like the Ownable and
+// AccessControl contracts of OpenZeppelin, with the same events.
+//
+// Every role has an admin role whose members grant and revoke it,
+// DefaultAdminRole unless SetRoleAdmin changed it. Failed checks
+// return errors, which revert a precompile call with the error as
+// reason when a handler returns them:
+//
+//	if err := access.Get(ctx.Factory).OnlyOwner(ctx.Caller); err != nil {
+//		return err
+//	}
+package access
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrNotOwner    = errors.New("caller is not the owner")
+	ErrMissingRole = errors.New("missing role")
+	ErrZeroAddress = errors.New("zero address")
+)
+
+// DefaultAdminRole is the admin role of every role by default.
+var DefaultAdminRole = common.Hash{}
+
+var (
+	// OwnershipTransferredTopic is the topic of OwnershipTransferred(address,address)
+	OwnershipTransferredTopic = crypto.Keccak256Hash([]byte("OwnershipTransferred(address,address)"))
+	// RoleGrantedTopic is the topic of RoleGranted(bytes32,address,address)
+	RoleGrantedTopic = crypto.Keccak256Hash([]byte("RoleGranted(bytes32,address,address)"))
+	// RoleRevokedTopic is the topic of RoleRevoked(bytes32,address,address)
+	RoleRevokedTopic = crypto.Keccak256Hash([]byte("RoleRevoked(bytes32,address,address)"))
+	// RoleAdminChangedTopic is the topic of RoleAdminChanged(bytes32,bytes32,bytes32)
+	RoleAdminChangedTopic = crypto.Keccak256Hash([]byte("RoleAdminChanged(bytes32,bytes32,bytes32)"))
+)
+
+// Names of the state of Access.
+const (
+	ownerName   = "owner"
+	membersName = "roleMembers"
+	adminsName  = "roleAdmins"
+)
+
+// memberKey is the key of the membership of Account in Role.
+type memberKey struct {
+	Role    common.Hash
+	Account common.Address
+}
+
+var memberKeyType = reflect.TypeOf(memberKey{})
+
+func init() {
+	ethtypes.RegisterType(memberKeyType)
+}
+
+// Role returns the role called name, the keccak256 hash of
+// the name like the role constants of OpenZeppelin.
+func Role(name string) common.Hash {
+	return crypto.Keccak256Hash([]byte(name))
+}
+
+// Access is the owner and the roles of a contract
+// in the state of a TypeFactory.
+type Access struct {
+	tf      *ethtypes.TypeFactory
+	owner   ethtypes.StateVariable
+	members ethtypes.Map
+	admins  ethtypes.Map
+}
+
+// New creates the access control of the contract in tf, owned by owner,
+// who is also granted DefaultAdminRole.
+func New(tf *ethtypes.TypeFactory, owner common.Address) *Access {
+	if owner == (common.Address{}) {
+		panic(ErrZeroAddress)
+	}
+
+	a := &Access{
+		tf:      tf,
+		owner:   tf.NewVariable(ownerName, owner),
+		members: tf.NewMap(membersName, memberKeyType, ethtypes.BoolType),
+		admins:  tf.NewMap(adminsName, ethtypes.HashType, ethtypes.HashType),
+	}
+	a.emit(OwnershipTransferredTopic, common.Hash{}, owner.Hash())
+	a.grant(DefaultAdminRole, owner, owner)
+
+	return a
+}
+
+// Get opens the access control created by New in tf.
+func Get(tf *ethtypes.TypeFactory) *Access {
+	return &Access{
+		tf:      tf,
+		owner:   tf.GetVariable(ownerName, ethtypes.AddressType),
+		members: tf.GetMap(membersName, memberKeyType, ethtypes.BoolType),
+		admins:  tf.GetMap(adminsName, ethtypes.HashType, ethtypes.HashType),
+	}
+}
+
+func (a *Access) Owner() common.Address {
+	var owner common.Address
+	a.owner.Get(&owner)
+	return owner
+}
+
+// OnlyOwner returns ErrNotOwner unless caller is the owner.
+func (a *Access) OnlyOwner(caller common.Address) error {
+	if caller != a.Owner() {
+		return fmt.Errorf("%w: %v", ErrNotOwner, caller.Hex())
+	}
+
+	return nil
+}
+
+// TransferOwnership makes newOwner the owner, if caller is the owner.
+// Roles, including DefaultAdminRole, are not transferred.
+func (a *Access) TransferOwnership(caller, newOwner common.Address) error {
+	if err := a.OnlyOwner(caller); err != nil {
+		return err
+	}
+	if newOwner == (common.Address{}) {
+		return ErrZeroAddress
+	}
+
+	a.owner.Set(newOwner)
+	a.emit(OwnershipTransferredTopic, caller.Hash(), newOwner.Hash())
+
+	return nil
+}
+
+func (a *Access) HasRole(role common.Hash, account common.Address) bool {
+	var member bool
+	a.members.Get(memberKey{Role: role, Account: account}, &member)
+	return member
+}
+
+// OnlyRole returns ErrMissingRole unless caller has role.
+func (a *Access) OnlyRole(role common.Hash, caller common.Address) error {
+	if !a.HasRole(role, caller) {
+		return fmt.Errorf("%w: %v lacks %v", ErrMissingRole, caller.Hex(), role.Hex())
+	}
+
+	return nil
+}
+
+// RoleAdmin returns the role whose members grant and revoke role.
+func (a *Access) RoleAdmin(role common.Hash) common.Hash {
+	var admin common.Hash
+	a.admins.Get(role, &admin)
+	return admin
+}
+
+// GrantRole grants role to account, if caller has the admin role of role.
+// Granting a role to a member does nothing.
+func (a *Access) GrantRole(caller common.Address, role common.Hash, account common.Address) error {
+	if err := a.OnlyRole(a.RoleAdmin(role), caller); err != nil {
+		return err
+	}
+	if account == (common.Address{}) {
+		return ErrZeroAddress
+	}
+
+	a.grant(role, account, caller)
+	return nil
+}
+
+// RevokeRole revokes role from account, if caller has the admin role of
+// role. Revoking a role from an account without it does nothing.
+func (a *Access) RevokeRole(caller common.Address, role common.Hash, account common.Address) error {
+	if err := a.OnlyRole(a.RoleAdmin(role), caller); err != nil {
+		return err
+	}
+
+	a.revoke(role, account, caller)
+	return nil
+}
+
+// RenounceRole revokes role from caller.
+func (a *Access) RenounceRole(caller common.Address, role common.Hash) {
+	a.revoke(role, caller, caller)
+}
+
+// SetRoleAdmin makes adminRole the admin role of role,
+// if caller has the current admin role of role.
+func (a *Access) SetRoleAdmin(caller common.Address, role, adminRole common.Hash) error {
+	previous := a.RoleAdmin(role)
+	if err := a.OnlyRole(previous, caller); err != nil {
+		return err
+	}
+
+	a.admins.Set(role, adminRole)
+	a.emit(RoleAdminChangedTopic, role, previous, adminRole)
+
+	return nil
+}
+
+func (a *Access) grant(role common.Hash, account, sender common.Address) {
+	if a.HasRole(role, account) {
+		return
+	}
+
+	a.members.Set(memberKey{Role: role, Account: account}, true)
+	a.emit(RoleGrantedTopic, role, account.Hash(), sender.Hash())
+}
+
+func (a *Access) revoke(role common.Hash, account, sender common.Address) {
+	if !a.HasRole(role, account) {
+		return
+	}
+
+	a.members.Del(memberKey{Role: role, Account: account})
+	a.emit(RoleRevokedTopic, role, account.Hash(), sender.Hash())
+}
+
+// emit emits an event whose arguments are all indexed.
+func (a *Access) emit(topic common.Hash, args ...common.Hash) {
+	a.tf.AddLog(append([]common.Hash{topic}, args...), nil)
+}
@@ -0,0 +1,87 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/TheStarBoys/ethtypes/precompile"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	owner = common.HexToAddress("0x0a")
+	alice = common.HexToAddress("0xa1")
+	bob   = common.HexToAddress("0xb0")
+	addr  = common.HexToAddress("0x100")
+
+	minter  = Role("MINTER_ROLE")
+	manager = Role("MANAGER_ROLE")
+)
+
+func TestAccess(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+
+	a := New(tf, owner)
+	assert.Equal(t, owner, a.Owner())
+	assert.NoError(t, a.OnlyOwner(owner))
+	assert.ErrorIs(t, a.OnlyOwner(alice), ErrNotOwner)
+	assert.True(t, a.HasRole(DefaultAdminRole, owner))
+
+	// the default admin grants and revokes every role
+	assert.NoError(t, a.GrantRole(owner, minter, alice))
+	assert.True(t, a.HasRole(minter, alice))
+	assert.NoError(t, a.OnlyRole(minter, alice))
+	assert.ErrorIs(t, a.OnlyRole(minter, bob), ErrMissingRole)
+	assert.ErrorIs(t, a.GrantRole(alice, minter, bob), ErrMissingRole)
+
+	// managers administer minters
+	assert.NoError(t, a.SetRoleAdmin(owner, minter, manager))
+	assert.Equal(t, manager, a.RoleAdmin(minter))
+	assert.ErrorIs(t, a.RevokeRole(owner, minter, alice), ErrMissingRole)
+	assert.NoError(t, a.GrantRole(owner, manager, bob))
+	assert.NoError(t, a.RevokeRole(bob, minter, alice))
+	assert.False(t, a.HasRole(minter, alice))
+
+	a.RenounceRole(bob, manager)
+	assert.False(t, a.HasRole(manager, bob))
+
+	assert.ErrorIs(t, a.TransferOwnership(alice, alice), ErrNotOwner)
+	assert.ErrorIs(t, a.TransferOwnership(owner, common.Address{}), ErrZeroAddress)
+	assert.NoError(t, a.TransferOwnership(owner, alice))
+	assert.Equal(t, alice, Get(tf).Owner())
+
+	logs := statedb.Logs()
+	assert.Equal(t, []common.Hash{OwnershipTransferredTopic, {}, owner.Hash()}, logs[0].Topics)
+	assert.Equal(t, []common.Hash{RoleGrantedTopic, DefaultAdminRole, owner.Hash(), owner.Hash()}, logs[1].Topics)
+	assert.Equal(t, []common.Hash{OwnershipTransferredTopic, owner.Hash(), alice.Hash()}, logs[len(logs)-1].Topics)
+}
+
+func TestRevertReason(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+	New(tf, owner)
+
+	c, err := precompile.New(`[{"type": "function", "name": "pause", "inputs": [], "outputs": []}]`, addr, map[string]interface{}{
+		"pause": func(ctx *precompile.Context) error {
+			return Get(ctx.Factory).OnlyOwner(ctx.Caller)
+		},
+	})
+	assert.NoError(t, err)
+	input, _ := c.ABI().Pack("pause")
+
+	_, err = c.Bind(statedb, owner).Run(input)
+	assert.NoError(t, err)
+
+	ret, err := c.Bind(statedb, alice).Run(input)
+	assert.Equal(t, vm.ErrExecutionReverted, err)
+	reason, _ := abi.UnpackRevert(ret)
+	assert.Equal(t, "caller is not the owner: "+alice.Hex(), reason)
+}
@@ -1,8 +1,11 @@
 package ethtypes
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type BasicArray struct {
@@ -10,6 +13,10 @@ type BasicArray struct {
 	len   StateVariable
 	typ   reflect.Type
 	state *ContractState
+	// tree commits to the elements if it is not nil
+	tree *merkleTree
+	// enum of the elements if they are EnumValues
+	enum *Enum
 }
 
 const (
@@ -60,6 +67,11 @@ func (a *BasicArray) Len() int {
 	return length
 }
 
+// setLen changes the length without touching any element.
+func (a *BasicArray) setLen(length int) {
+	a.len.Set(length)
+}
+
 func (a *BasicArray) Name() string {
 	return a.name
 }
@@ -84,24 +96,45 @@ func (a *BasicArray) ElemType() reflect.Type {
 	return a.typ
 }
 
-func (a *BasicArray) CopyFrom(src Array, dstFrom, srcFrom, srcTo int) {
-	count := srcTo - srcFrom
-	if count > a.Len()-dstFrom {
-		panic(ErrIndexOutOfRange)
-	}
+func (a *BasicArray) CopyFrom(src Array, dstFrom, srcFrom, srcTo int) error {
+	return a.copyFrom(src, dstFrom, srcFrom, srcTo, a.Len())
+}
 
+// copyFrom validates the ranges of both sides, the destination
+// range must be within [0, length).
+func (a *BasicArray) copyFrom(src Array, dstFrom, srcFrom, srcTo, length int) error {
+	if srcFrom < 0 || srcFrom > srcTo || srcTo > src.Len() {
+		return fmt.Errorf("%w: source range [%d, %d) of length %d", ErrIndexOutOfRange, srcFrom, srcTo, src.Len())
+	}
+	if dstFrom < 0 || srcTo-srcFrom > length-dstFrom {
+		return fmt.Errorf("%w: copy %d elements to %d of length %d", ErrIndexOutOfRange, srcTo-srcFrom, dstFrom, length)
+	}
 	if src.ElemType().Kind() != a.ElemType().Kind() {
-		panic("kind not match")
+		return fmt.Errorf("%w: expect kind: %v, actual kind: %v", ErrKindMismatch, a.ElemType().Kind(), src.ElemType().Kind())
 	}
 
-	for i := 0; i < count && srcFrom < srcTo; i++ {
+	a.move(src, dstFrom, srcFrom, srcTo)
+	return nil
+}
+
+// move copies [srcFrom, srcTo) of src to dstFrom like memmove:
+// when copying to a higher index it goes from the last element,
+// so an overlapping source is not overwritten before it is read.
+func (a *BasicArray) move(src Array, dstFrom, srcFrom, srcTo int) {
+	copyElem := func(i int) {
 		val := reflect.New(src.ElemType()).Interface()
-		src.Get(srcFrom, val)
-		// byts := src.getRaw(srcFrom)
-		srcFrom++
-		a.Set(dstFrom, val)
-		// a.setRaw(dstFrom, byts)
-		dstFrom++
+		src.Get(i, val)
+		a.Set(dstFrom+i-srcFrom, val)
+	}
+
+	if dstFrom > srcFrom {
+		for i := srcTo - 1; i >= srcFrom; i-- {
+			copyElem(i)
+		}
+		return
+	}
+	for i := srcFrom; i < srcTo; i++ {
+		copyElem(i)
 	}
 }
 
@@ -114,22 +147,181 @@ func (a *BasicArray) Del(index int) {
 	case a.Len() - 1:
 		a.getElem(index).Del()
 	default:
-		a.CopyFrom(a, index, index+1, a.Len())
+		a.move(a, index, index+1, a.Len())
 		a.getElem(a.Len() - 1).Del()
 	}
 }
 
+func (a *BasicArray) GetRange(from, to int, vals interface{}) {
+	checkRange(from, to, a.Len())
+	a.getRange(from, to, vals)
+}
+
+func (a *BasicArray) SetRange(from int, vals interface{}) {
+	a.setRange(from, vals, a.Len())
+}
+
+func (a *BasicArray) Swap(i, j int) {
+	if length := a.Len(); i < 0 || i >= length || j < 0 || j >= length {
+		panic(ErrIndexOutOfRange)
+	}
+
+	a.swap(i, j)
+}
+
+func (a *BasicArray) Reverse() {
+	a.reverse(0, a.Len())
+}
+
+func (a *BasicArray) Fill(val interface{}) {
+	a.fill(val, 0, a.Len())
+}
+
+// The helpers below take the bounds from their caller, so that
+// BasicSlice can apply them to its length rather than the capacity.
+
+func (a *BasicArray) getRange(from, to int, vals interface{}) {
+	v := reflect.ValueOf(vals)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("vals must be pointer to slice")
+	}
+
+	res := reflect.MakeSlice(v.Elem().Type(), to-from, to-from)
+	for i := from; i < to; i++ {
+		a.getElem(i).Get(res.Index(i - from).Addr().Interface())
+	}
+	v.Elem().Set(res)
+}
+
+func (a *BasicArray) setRange(from int, vals interface{}, length int) {
+	v := reflect.ValueOf(vals)
+	if v.Kind() != reflect.Slice {
+		panic("vals must be slice")
+	}
+	checkRange(from, from+v.Len(), length)
+
+	for i := 0; i < v.Len(); i++ {
+		a.getElem(from + i).Set(v.Index(i).Interface())
+	}
+}
+
+// swap only writes elements which are not equal.
+func (a *BasicArray) swap(i, j int) {
+	ei, ej := a.getElem(i), a.getElem(j)
+	ri, rj := ei.raw(), ej.raw()
+	if !bytes.Equal(ri, rj) {
+		ei.setRaw(rj)
+		ej.setRaw(ri)
+	}
+}
+
+// reverse reads every element in [from, to) once, and only
+// writes elements which differ from their mirror.
+func (a *BasicArray) reverse(from, to int) {
+	for i, j := from, to-1; i < j; i, j = i+1, j-1 {
+		a.swap(i, j)
+	}
+}
+
+// fill encodes val once, and only writes elements
+// in [from, to) which are not equal to it.
+func (a *BasicArray) fill(val interface{}, from, to int) {
+	byts := a.getElem(from).encode(val)
+	for i := from; i < to; i++ {
+		if elem := a.getElem(i); !bytes.Equal(elem.raw(), byts) {
+			elem.setRaw(byts)
+		}
+	}
+}
+
+// checkRange panics if [from, to) is not a range of indexes
+// of an array of length.
+func checkRange(from, to, length int) {
+	if from < 0 || from > to || to > length {
+		panic(ErrIndexOutOfRange)
+	}
+}
+
 func (a *BasicArray) isOutOfRange(index int) bool {
 	return a.Len() <= index || index < 0
 }
 
-func (a *BasicArray) getElem(index int) StateVariable {
+func (a *BasicArray) getElem(index int) *BasicStateVariable {
 	// prefix + name + index
 	indexStr := fmt.Sprintf("%s_%d", arrayPrefix+a.name, index)
 	v, err := GetBasicStateVariable(a.state, indexStr, a.typ)
 	if err != nil {
 		panic(fmt.Sprintf("getElem err: %v", err))
 	}
+	v.enum = a.enum
+	if a.tree != nil {
+		v.onWrite = func(raw []byte) {
+			a.tree.update(index, arrayLeaf(raw))
+		}
+	}
 
 	return v
 }
+
+// commit keeps the Merkle root of the elements up to date,
+// it must be used from the creation of the array on.
+func (a *BasicArray) commit() {
+	a.tree = newMerkleTree(a.state, arrayPrefix+a.name)
+}
+
+// Root returns the Merkle root of the elements,
+// or the zero hash if the array is not committed.
+func (a *BasicArray) Root() common.Hash {
+	if a.tree == nil {
+		return common.Hash{}
+	}
+
+	return a.tree.root()
+}
+
+func (a *BasicArray) Proof(index int) (*MerkleProof, error) {
+	return a.proof(index, a.Len())
+}
+
+func (a *BasicArray) proof(index, length int) (*MerkleProof, error) {
+	if a.tree == nil {
+		return nil, ErrNotCommitted
+	}
+	if index < 0 || index >= length {
+		return nil, fmt.Errorf("%w: index %d of length %d", ErrIndexOutOfRange, index, length)
+	}
+
+	return &MerkleProof{
+		Index:    uint64(index),
+		Value:    a.getElem(index).raw(),
+		Siblings: a.tree.proof(index),
+	}, nil
+}
+
+func (a *BasicArray) Sort(less Less) {
+	SortArray(a, less)
+}
+
+func (a *BasicArray) IsSorted(less Less) bool {
+	return IsArraySorted(a, less)
+}
+
+func (a *BasicArray) SearchSorted(val interface{}, less Less) int {
+	return SearchArray(a, val, less)
+}
+
+func (a *BasicArray) Range(fn func(index int, val interface{}) bool) {
+	RangeArray(a, fn)
+}
+
+func (a *BasicArray) Iter() Iterator {
+	return IterArray(a, 0, -1, false)
+}
+
+func (a *BasicArray) ReverseIter() Iterator {
+	return IterArray(a, 0, -1, true)
+}
+
+func (a *BasicArray) Page(offset, limit int) Iterator {
+	return IterArray(a, offset, limit, false)
+}
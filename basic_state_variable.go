@@ -1,7 +1,6 @@
 package ethtypes
 
 import (
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -14,6 +13,11 @@ type BasicStateVariable struct {
 	name  string
 	loc   common.Hash
 	typ   reflect.Type
+	// onWrite is called with the stored form
+	// of the value after every change
+	onWrite func(raw []byte)
+	// enum of the value if it is an EnumValue
+	enum *Enum
 }
 
 const (
@@ -47,12 +51,23 @@ func GetBasicStateVariable(state *ContractState, variableName string, typ reflec
 		typ:   typ,
 	}
 
-	sv.loc = sha256.Sum256([]byte(stateVariablePrefix + variableName))
+	sv.loc = state.hash(stateVariablePrefix + variableName)
 
 	return sv, nil
 }
 
+// variableLoc returns the location of the variable
+// called name in the root namespace.
+func variableLoc(name string) common.Hash {
+	return nameHash(common.Hash{}, stateVariablePrefix+name)
+}
+
 func (sv *BasicStateVariable) IsAssigned() bool {
+	if isSingleSlot(sv.typ) {
+		_, ok := sv.slot()
+		return ok
+	}
+
 	return sv.state.Exists(sv.loc)
 }
 
@@ -69,21 +84,52 @@ func (sv *BasicStateVariable) Name() string {
 }
 
 func (sv *BasicStateVariable) Del() {
-	sv.state.Delete(sv.loc)
+	if isSingleSlot(sv.typ) {
+		sv.state.setSlot(sv.loc, common.Hash{})
+		if marker := assignedSlot(sv.loc); sv.state.getSlot(marker) != (common.Hash{}) {
+			sv.state.setSlot(marker, common.Hash{})
+		}
+		sv.dropLegacy()
+	} else {
+		sv.state.Delete(sv.loc)
+	}
+	if sv.onWrite != nil {
+		sv.onWrite(nil)
+	}
 }
 
 func (sv *BasicStateVariable) Set(val interface{}) {
+	sv.setRaw(sv.encode(val))
+}
+
+// encode checks val against the type of the variable
+// and returns its stored form.
+func (sv *BasicStateVariable) encode(val interface{}) []byte {
 	v := reflect.ValueOf(val)
 	for count := 3; count > 0 && v.Kind() == reflect.Ptr; count-- {
 		// panic("cannot set pointer variable")
 		v = v.Elem()
 	}
-	if v.Kind() != sv.typ.Kind() {
-		panic(fmt.Sprintf("expect kind: %v, actual kind: %v", sv.typ.Kind(), v.Kind()))
+	if sv.state.strict {
+		if v.Type() != sv.typ {
+			v = checkValue("set", sv.name, sv.typ, v)
+			val = v.Interface()
+		}
+	} else {
+		if v.Kind() != sv.typ.Kind() {
+			panic(fmt.Sprintf("expect kind: %v, actual kind: %v", sv.typ.Kind(), v.Kind()))
+		}
+
+		if v.Kind() == reflect.Struct && v.Type().Name() != sv.typ.Name() {
+			panic(fmt.Sprintf("expect type: %v, actual type: %v", sv.typ.Name(), v.Type().Name()))
+		}
 	}
 
-	if v.Kind() == reflect.Struct && v.Type().Name() != sv.typ.Name() {
-		panic(fmt.Sprintf("expect type: %v, actual type: %v", sv.typ.Name(), v.Type().Name()))
+	if sv.typ == EnumType {
+		sv.enum.check(sv.name, v.Interface().(EnumValue))
+	}
+	if isSingleSlot(sv.typ) {
+		return encodeSingle(sv.typ, v)
 	}
 
 	byts, err := json.Marshal(val)
@@ -91,7 +137,43 @@ func (sv *BasicStateVariable) Set(val interface{}) {
 		panic("cannot marshal val")
 	}
 
-	sv.state.Write(sv.loc, byts)
+	return byts
+}
+
+// raw returns the stored form of the value,
+// which is empty if it is not assigned.
+func (sv *BasicStateVariable) raw() []byte {
+	if isSingleSlot(sv.typ) {
+		if slot, ok := sv.slot(); ok {
+			return slot.Bytes()
+		}
+		return nil
+	}
+
+	return sv.state.Read(sv.loc)
+}
+
+// setRaw stores a value returned by raw or encode.
+func (sv *BasicStateVariable) setRaw(byts []byte) {
+	if len(byts) == 0 {
+		sv.Del()
+		return
+	}
+
+	if isSingleSlot(sv.typ) {
+		slot := common.BytesToHash(byts)
+		sv.state.setSlot(sv.loc, slot)
+		if slot == (common.Hash{}) {
+			// slot reads a legacy value before the marker, so drop it
+			sv.dropLegacy()
+			sv.state.setSlot(assignedSlot(sv.loc), common.Hash{31: 1})
+		}
+	} else {
+		sv.state.Write(sv.loc, byts)
+	}
+	if sv.onWrite != nil {
+		sv.onWrite(byts)
+	}
 }
 
 func (sv *BasicStateVariable) Get(val interface{}) bool {
@@ -101,14 +183,30 @@ func (sv *BasicStateVariable) Get(val interface{}) bool {
 	}
 
 	elem := v.Elem()
-	if elem.Kind() != sv.typ.Kind() {
+	if sv.state.strict {
+		// the stored value must widen to the type of val
+		if !Widens(sv.typ, elem.Type()) {
+			panic(&TypeError{Op: "get", Name: sv.name, Expected: sv.typ, Actual: elem.Type()})
+		}
+	} else if elem.Kind() != sv.typ.Kind() {
 		panic(fmt.Sprintf("expect kind: %v, actual kind: %v", sv.typ.Kind(), elem.Kind()))
 	}
+	if isSingleSlot(sv.typ) {
+		slot, ok := sv.slot()
+		decodeSingle(slot, elem)
+		if sv.enum != nil && elem.Type() == EnumType {
+			elem.Set(reflect.ValueOf(EnumValue{enum: sv.enum, index: enumIndex(slot)}))
+		}
+		return ok
+	}
 	bts := sv.state.Read(sv.loc)
 
 	// returns zero value if err != nil
 	err := json.Unmarshal(bts, val)
 	if err != nil {
+		if sv.state.strict && len(bts) != 0 {
+			panic(fmt.Errorf("%w: decode %s as %v: %v", ErrTypeMismatch, sv.name, elem.Type(), err))
+		}
 		zeroVal := reflect.Zero(elem.Type())
 		elem.Set(zeroVal)
 	}
@@ -116,6 +214,41 @@ func (sv *BasicStateVariable) Get(val interface{}) bool {
 	return sv.IsAssigned()
 }
 
+// slot returns the slot of a single-slot value and whether it is assigned.
+// A zero slot is assigned if its assigned slot is set. Fixed bytes used to
+// be stored as JSON, a zero slot also falls back to such a legacy value.
+func (sv *BasicStateVariable) slot() (common.Hash, bool) {
+	slot := sv.state.getSlot(sv.loc)
+	if slot != (common.Hash{}) {
+		return slot, true
+	}
+	if isFixedBytes(sv.typ) {
+		if byts := sv.state.Read(sv.loc); len(byts) != 0 {
+			return sv.legacy(byts), true
+		}
+	}
+
+	return slot, sv.state.getSlot(assignedSlot(sv.loc)) != (common.Hash{})
+}
+
+// legacy decodes fixed bytes stored as JSON by earlier versions.
+func (sv *BasicStateVariable) legacy(byts []byte) common.Hash {
+	v := reflect.New(sv.typ)
+	if err := json.Unmarshal(byts, v.Interface()); err != nil {
+		panic(fmt.Errorf("%w: decode legacy %s as %v: %v", ErrTypeMismatch, sv.name, sv.typ, err))
+	}
+
+	return encodeFixed(sv.typ, v.Elem())
+}
+
+// dropLegacy deletes the JSON of legacy fixed bytes, which a non-zero
+// slot hides, so it cannot reappear once the slot is zero.
+func (sv *BasicStateVariable) dropLegacy() {
+	if isFixedBytes(sv.typ) && sv.state.Exists(sv.loc) {
+		sv.state.Delete(sv.loc)
+	}
+}
+
 func (sv *BasicStateVariable) CopyFrom(src StateVariable) {
 	if src.Type().Kind() != sv.Type().Kind() {
 		panic("kind not match")
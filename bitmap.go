@@ -0,0 +1,219 @@
+package ethtypes
+
+import (
+	"math/big"
+	mathbits "math/bits"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	bitmapPrefix      = "bitmap_"
+	bitmapIndexPrefix = "bitmap_index_"
+	bitmapWordBits    = 256
+	// bitmapLevels is the number of index levels above the
+	// flags, the top level is a single word.
+	bitmapLevels = 3
+	// MaxBitmapIndex is the largest index of a Bitmap, the
+	// single top word covers 256^(bitmapLevels+1) flags.
+	MaxBitmapIndex = 1<<32 - 1
+)
+
+// BasicBitmap keeps the flag of index i in bit i%256 of the word slot
+// i/256, like the BitMaps library of OpenZeppelin. The number of set
+// flags is kept in the length slot.
+//
+// Bit j of a word of index level l+1 tells whether the word j of level l
+// is non-zero, so NextSet and Clear skip unset words reading at most two
+// slots per level, however sparse the flags are.
+type BasicBitmap struct {
+	state *ContractState
+	name  string
+	// levels[0] is the location of the flags,
+	// levels[l] the one of index level l
+	levels [bitmapLevels + 1]common.Hash
+}
+
+var _ Bitmap = (*BasicBitmap)(nil)
+
+// NewBasicBitmap returns the bitmap called name with every flag cleared.
+func NewBasicBitmap(state *ContractState, name string) (*BasicBitmap, error) {
+	b, err := GetBasicBitmap(state, name)
+	if err != nil {
+		return nil, err
+	}
+	b.Clear()
+
+	return b, nil
+}
+
+func GetBasicBitmap(state *ContractState, name string) (*BasicBitmap, error) {
+	b := &BasicBitmap{
+		state: state,
+		name:  name,
+	}
+	b.levels[0] = state.hash(bitmapPrefix + name)
+	for l := 1; l <= bitmapLevels; l++ {
+		b.levels[l] = state.hash(bitmapIndexPrefix + strconv.Itoa(l) + "_" + name)
+	}
+
+	return b, nil
+}
+
+func (b *BasicBitmap) Name() string {
+	return b.name
+}
+
+func (b *BasicBitmap) Set(i int) {
+	b.SetRange(i, i+1)
+}
+
+func (b *BasicBitmap) Unset(i int) {
+	b.UnsetRange(i, i+1)
+}
+
+func (b *BasicBitmap) Test(i int) bool {
+	checkIndex(i)
+	return b.word(0, i/bitmapWordBits).Bit(i%bitmapWordBits) == 1
+}
+
+func (b *BasicBitmap) Count() int {
+	return int(b.state.getSlot(lengthSlot(b.levels[0])).Big().Int64())
+}
+
+func (b *BasicBitmap) NextSet(i int) (int, bool) {
+	checkIndex(i)
+	return b.next(0, i)
+}
+
+// next returns the first set bit of level l from i on.
+func (b *BasicBitmap) next(l, i int) (int, bool) {
+	w := i / bitmapWordBits
+	if bit, ok := nextBit(b.word(l, w), i%bitmapWordBits); ok {
+		return w*bitmapWordBits + bit, true
+	}
+	if l == bitmapLevels {
+		return 0, false
+	}
+
+	// the level above knows the next non-zero word
+	w, ok := b.next(l+1, w+1)
+	if !ok {
+		return 0, false
+	}
+	bit, _ := nextBit(b.word(l, w), 0)
+	return w*bitmapWordBits + bit, true
+}
+
+func (b *BasicBitmap) SetRange(from, to int) {
+	b.update(from, to, 1)
+}
+
+func (b *BasicBitmap) UnsetRange(from, to int) {
+	b.update(from, to, 0)
+}
+
+// Clear writes each non-zero word of every level once.
+func (b *BasicBitmap) Clear() {
+	for l := 0; l < bitmapLevels; l++ {
+		for w, ok := b.next(l+1, 0); ok; w, ok = b.next(l+1, w+1) {
+			b.state.setSlot(chunkSlot(b.levels[l], w), common.Hash{})
+		}
+	}
+	if b.word(bitmapLevels, 0).Sign() != 0 {
+		b.state.setSlot(chunkSlot(b.levels[bitmapLevels], 0), common.Hash{})
+	}
+	if b.Count() != 0 {
+		b.setCount(0)
+	}
+}
+
+// update sets the flags of [from, to) to bit, writing each
+// changed word and the count once.
+func (b *BasicBitmap) update(from, to int, bit uint) {
+	checkIndex(from)
+	if to < from || int64(to) > MaxBitmapIndex+1 {
+		panic(ErrIndexOutOfRange)
+	}
+
+	count := b.Count()
+	for w := from / bitmapWordBits; w*bitmapWordBits < to; w++ {
+		if bit == 0 {
+			// only non-zero words have flags to clear
+			next, ok := b.next(1, w)
+			if !ok || next*bitmapWordBits >= to {
+				break
+			}
+			w = next
+		}
+		old := b.word(0, w)
+		word := new(big.Int).Set(old)
+		i := w * bitmapWordBits
+		if i < from {
+			i = from
+		}
+		for ; i < to && i < (w+1)*bitmapWordBits; i++ {
+			word.SetBit(word, i%bitmapWordBits, bit)
+		}
+		if word.Cmp(old) == 0 {
+			continue
+		}
+
+		count += popCount(word) - popCount(old)
+		b.setWord(0, w, old, word)
+	}
+
+	if count != b.Count() {
+		b.setCount(count)
+	}
+}
+
+func (b *BasicBitmap) word(l, w int) *big.Int {
+	return b.state.getSlot(chunkSlot(b.levels[l], w)).Big()
+}
+
+// setWord changes the word w of level l from old to word, and
+// updates the index levels above if it becomes zero or non-zero.
+func (b *BasicBitmap) setWord(l, w int, old, word *big.Int) {
+	b.state.setSlot(chunkSlot(b.levels[l], w), common.BigToHash(word))
+	if l == bitmapLevels || (old.Sign() == 0) == (word.Sign() == 0) {
+		return
+	}
+
+	var flag uint
+	if word.Sign() != 0 {
+		flag = 1
+	}
+	above := b.word(l+1, w/bitmapWordBits)
+	b.setWord(l+1, w/bitmapWordBits, above, new(big.Int).SetBit(above, w%bitmapWordBits, flag))
+}
+
+func (b *BasicBitmap) setCount(count int) {
+	b.state.setSlot(lengthSlot(b.levels[0]), common.BigToHash(big.NewInt(int64(count))))
+}
+
+func checkIndex(i int) {
+	if i < 0 || int64(i) > MaxBitmapIndex {
+		panic(ErrIndexOutOfRange)
+	}
+}
+
+// nextBit returns the first set bit of word from bit on.
+func nextBit(word *big.Int, bit int) (int, bool) {
+	rest := new(big.Int).Rsh(word, uint(bit))
+	if rest.Sign() == 0 {
+		return 0, false
+	}
+
+	return bit + int(rest.TrailingZeroBits()), true
+}
+
+func popCount(word *big.Int) int {
+	var n int
+	for _, w := range word.Bits() {
+		n += mathbits.OnesCount(uint(w))
+	}
+
+	return n
+}
@@ -0,0 +1,96 @@
+package ethtypes
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitmap(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	claimed := tf.NewBitmap("claimed")
+	claimed.Set(3)
+	claimed.Set(300)
+	assert.True(t, claimed.Test(3))
+	assert.True(t, claimed.Test(300))
+	assert.False(t, claimed.Test(4))
+	assert.Equal(t, 2, claimed.Count())
+
+	// setting a flag again writes nothing
+	meter.Reset()
+	claimed.Set(3)
+	assert.Equal(t, uint64(0), meter.Writes())
+	// 256 flags share a slot, the count is written once
+	claimed.SetRange(0, 256)
+	assert.Equal(t, uint64(2), meter.Writes())
+	assert.Equal(t, 257, claimed.Count())
+
+	next, ok := claimed.NextSet(256)
+	assert.True(t, ok)
+	assert.Equal(t, 300, next)
+	_, ok = claimed.NextSet(301)
+	assert.False(t, ok)
+
+	claimed.UnsetRange(1, 300)
+	assert.Equal(t, 2, claimed.Count())
+	next, _ = claimed.NextSet(1)
+	assert.Equal(t, 300, next)
+	claimed.Unset(0)
+	claimed.Unset(1000)
+	assert.Equal(t, 1, claimed.Count())
+
+	assert.Panics(t, func() { claimed.Set(-1) })
+	assert.Panics(t, func() { claimed.Set(MaxBitmapIndex + 1) })
+
+	claimed.Clear()
+	assert.Equal(t, 0, claimed.Count())
+	_, ok = claimed.NextSet(0)
+	assert.False(t, ok)
+
+	claimed.Set(7)
+	c, err := tf.Open("claimed")
+	assert.NoError(t, err)
+	assert.True(t, c.(Bitmap).Test(7))
+	assert.Panics(t, func() { tf.GetArray("claimed", 0, BoolType) })
+}
+
+func TestBitmapSparse(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	flags := tf.NewBitmap("flags")
+	flags.Set(5)
+	flags.Set(1 << 24)
+	flags.Set(MaxBitmapIndex)
+
+	// the index levels skip the unset words in between
+	meter.Reset()
+	next, ok := flags.NextSet(6)
+	assert.True(t, ok)
+	assert.Equal(t, 1<<24, next)
+	next, ok = flags.NextSet(1<<24 + 1)
+	assert.True(t, ok)
+	assert.Equal(t, MaxBitmapIndex, next)
+	assert.True(t, meter.Reads() <= 16)
+
+	meter.Reset()
+	flags.UnsetRange(0, 1<<30)
+	assert.Equal(t, 1, flags.Count())
+	assert.True(t, meter.Reads() <= 32)
+
+	meter.Reset()
+	flags.Clear()
+	assert.Equal(t, 0, flags.Count())
+	assert.True(t, meter.Writes() <= 6)
+	_, ok = flags.NextSet(0)
+	assert.False(t, ok)
+}
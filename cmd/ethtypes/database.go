@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const (
+	leveldbCache   = 16
+	leveldbHandles = 16
+)
+
+// openDatabase opens either a geth chaindata directory or a JSON export of
+// an in-memory database. The export is an object mapping hex encoded keys
+// to hex encoded values, as written by exportDatabase.
+func openDatabase(datadir, memdump string) (ethdb.Database, error) {
+	switch {
+	case datadir != "" && memdump != "":
+		return nil, fmt.Errorf("-datadir and -memdump are mutually exclusive")
+	case datadir != "":
+		return rawdb.NewLevelDBDatabase(datadir, leveldbCache, leveldbHandles, "", true)
+	case memdump != "":
+		return loadMemoryDatabase(memdump)
+	default:
+		return nil, fmt.Errorf("one of -datadir or -memdump is required")
+	}
+}
+
+func loadMemoryDatabase(file string) (ethdb.Database, error) {
+	byts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]hexutil.Bytes
+	if err := json.Unmarshal(byts, &entries); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", file, err)
+	}
+
+	db := rawdb.NewMemoryDatabase()
+	for k, v := range entries {
+		key, err := hexutil.Decode(k)
+		if err != nil {
+			return nil, fmt.Errorf("decode key %s: %v", k, err)
+		}
+		if err := db.Put(key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// exportDatabase writes every entry of db in the format read by
+// loadMemoryDatabase.
+func exportDatabase(db ethdb.Database, file string) error {
+	entries := make(map[string]hexutil.Bytes)
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		entries[hexutil.Encode(it.Key())] = common.CopyBytes(it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	byts, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, byts, 0644)
+}
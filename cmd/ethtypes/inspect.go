@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/TheStarBoys/ethtypes"
+)
+
+const (
+	kindVariable    = "variable"
+	kindArray       = "array"
+	kindSlice       = "slice"
+	kindMap         = "map"
+	kindIterableMap = "iterablemap"
+)
+
+// Item describes a named state variable or container of a contract.
+type Item struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Type is the type of the variable, or the element/value type
+	// of a container.
+	Type string `json:"type"`
+	// KeyType is the key type of maps.
+	KeyType string `json:"keyType,omitempty"`
+	// Keys are the JSON encoded keys to read from a plain map,
+	// which cannot be enumerated.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// Entry is a single value read from the state. Path identifies the
+// value within its item, e.g. "Array[2]" or `Balances["0x12"]`.
+type Entry struct {
+	Path     string      `json:"path"`
+	Value    interface{} `json:"value"`
+	Assigned bool        `json:"assigned"`
+}
+
+func (it Item) types() (key, val reflect.Type, err error) {
+	val, err = ethtypes.ParseType(it.Type)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch it.Kind {
+	case kindMap, kindIterableMap:
+		if it.KeyType == "" {
+			return nil, nil, fmt.Errorf("%s: key type is required", it.Name)
+		}
+		key, err = ethtypes.ParseType(it.KeyType)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return key, val, nil
+}
+
+// read returns every entry of the item, in a deterministic order.
+func read(tf *ethtypes.TypeFactory, it Item) (entries []Entry, err error) {
+	// containers panic on malformed input
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("read %s: %v", it.Name, r)
+		}
+	}()
+
+	keyType, valType, err := it.types()
+	if err != nil {
+		return nil, err
+	}
+
+	switch it.Kind {
+	case kindVariable:
+		v := tf.GetVariable(it.Name, valType)
+		val := reflect.New(valType)
+		ok := v.Get(val.Interface())
+		entries = append(entries, newEntry(it.Name, val.Elem().Interface(), ok))
+	case kindArray:
+		entries = readArray(tf.GetArray(it.Name, 0, valType))
+	case kindSlice:
+		entries = readArray(tf.GetSlice(it.Name, 0, 0, valType))
+	case kindMap:
+		m := tf.GetMap(it.Name, keyType, valType)
+		for _, k := range it.Keys {
+			key := reflect.New(keyType)
+			if err := json.Unmarshal([]byte(k), key.Interface()); err != nil {
+				return nil, fmt.Errorf("decode key %s: %v", k, err)
+			}
+			val := reflect.New(valType)
+			ok := m.Get(key.Elem().Interface(), val.Interface())
+			entries = append(entries, newEntry(elemPath(it.Name, k), val.Elem().Interface(), ok))
+		}
+	case kindIterableMap:
+		m := tf.GetIterableMap(it.Name, keyType, valType)
+		for k, v := range ethtypes.GetIterableMapElems(m) {
+			entries = append(entries, newEntry(elemPath(it.Name, k), v, true))
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	default:
+		return nil, fmt.Errorf("unknown kind %q", it.Kind)
+	}
+
+	return entries, nil
+}
+
+// readElem returns the entry of the element in index of an array
+// or slice, reading only its length and the element itself.
+func readElem(tf *ethtypes.TypeFactory, it Item, index int) (entries []Entry, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("read %s: %v", it.Name, r)
+		}
+	}()
+
+	_, valType, err := it.types()
+	if err != nil {
+		return nil, err
+	}
+
+	var a ethtypes.Array
+	if it.Kind == kindArray {
+		a = tf.GetArray(it.Name, 0, valType)
+	} else {
+		a = tf.GetSlice(it.Name, 0, 0, valType)
+	}
+	if index >= a.Len() {
+		return nil, fmt.Errorf("%s: %w", it.Name, ethtypes.ErrIndexOutOfRange)
+	}
+	val := reflect.New(valType)
+	a.Get(index, val.Interface())
+
+	return []Entry{newEntry(fmt.Sprintf("%s[%d]", a.Name(), index), val.Elem().Interface(), true)}, nil
+}
+
+// newEntry keeps values such as big.Int, which only marshal
+// through a pointer receiver, addressable.
+func newEntry(path string, val interface{}, assigned bool) Entry {
+	v := reflect.ValueOf(val)
+	marshaler := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	if v.IsValid() && !v.Type().Implements(marshaler) && reflect.PtrTo(v.Type()).Implements(marshaler) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		val = ptr.Interface()
+	}
+
+	return Entry{path, val, assigned}
+}
+
+func readArray(a ethtypes.Array) []Entry {
+	var entries []Entry
+	for i, v := range ethtypes.GetArrayElems(a) {
+		entries = append(entries, newEntry(fmt.Sprintf("%s[%d]", a.Name(), i), v, true))
+	}
+
+	return entries
+}
+
+func elemPath(name, key string) string {
+	return fmt.Sprintf("%s[%s]", name, key)
+}
+
+// Change is an entry whose value differs between two states.
+type Change struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// diff compares two sets of entries by path. Entries missing
+// on one side are reported with a nil value.
+func diff(from, to []Entry) []Change {
+	values := func(entries []Entry) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, e := range entries {
+			if e.Assigned {
+				m[e.Path] = e.Value
+			}
+		}
+		return m
+	}
+	fromVals, toVals := values(from), values(to)
+
+	paths := make(map[string]struct{})
+	for p := range fromVals {
+		paths[p] = struct{}{}
+	}
+	for p := range toVals {
+		paths[p] = struct{}{}
+	}
+
+	var changes []Change
+	for p := range paths {
+		if !reflect.DeepEqual(fromVals[p], toVals[p]) {
+			changes = append(changes, Change{p, fromVals[p], toVals[p]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes
+}
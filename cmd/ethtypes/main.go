@@ -0,0 +1,325 @@
+// Command ethtypes inspects the storage of contracts whose state is
+// laid out by ethtypes, using the same slot hashing as ContractState.
+//
+// Usage:
+//
+//	ethtypes -datadir <chaindata> -root <hash> -addr <address> [-format text|json] <command> [flags]
+//
+// Commands:
+//
+//	get     read one variable, or one element of a container (-index, -key)
+//	list    read every element of a container
+//	dump    read every item described by a JSON spec file (-spec)
+//	diff    compare items between -root and -against
+//	export  write the database as a JSON file usable with -memdump
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+var (
+	datadirFlag = flag.String("datadir", "", "geth chaindata directory (LevelDB)")
+	memdumpFlag = flag.String("memdump", "", "JSON export of an in-memory database")
+	rootFlag    = flag.String("root", "", "state root to read")
+	addrFlag    = flag.String("addr", "", "contract address")
+	formatFlag  = flag.String("format", formatText, "output format: text or json")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Args()[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ethtypes:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: ethtypes [flags] get|list|dump|diff|export [command flags]\n")
+	flag.PrintDefaults()
+}
+
+func run(cmd string, args []string, w io.Writer) error {
+	if *formatFlag != formatText && *formatFlag != formatJSON {
+		return fmt.Errorf("unknown format %q", *formatFlag)
+	}
+
+	db, err := openDatabase(*datadirFlag, *memdumpFlag)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch cmd {
+	case "get":
+		return get(db, args, w)
+	case "list":
+		return list(db, args, w)
+	case "dump":
+		return dump(db, args, w)
+	case "diff":
+		return diffRoots(db, args, w)
+	case "export":
+		return export(db, args)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// itemFlags registers the flags describing a single item on fs.
+func itemFlags(fs *flag.FlagSet) *Item {
+	it := new(Item)
+	fs.StringVar(&it.Kind, "kind", kindVariable, "variable, array, slice, map or iterablemap")
+	fs.StringVar(&it.Name, "name", "", "name of the variable or container")
+	fs.StringVar(&it.Type, "type", "", "type of the variable, or element/value type of the container")
+	fs.StringVar(&it.KeyType, "keytype", "", "key type of a map")
+
+	return it
+}
+
+func factory(db ethdb.Database, root string) (*ethtypes.TypeFactory, error) {
+	if root == "" {
+		return nil, fmt.Errorf("state root is required")
+	}
+	if !common.IsHexAddress(*addrFlag) {
+		return nil, fmt.Errorf("invalid contract address %q", *addrFlag)
+	}
+
+	return ethtypes.OpenReadOnlyTypeFactory(common.HexToHash(root), state.NewDatabase(db), common.HexToAddress(*addrFlag))
+}
+
+func get(db ethdb.Database, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	it := itemFlags(fs)
+	index := fs.Int("index", -1, "element index of an array or slice")
+	key := fs.String("key", "", "JSON encoded key of a map element")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *key != "" {
+		if it.Kind != kindMap && it.Kind != kindIterableMap {
+			return fmt.Errorf("-key requires a map")
+		}
+		// iterable maps keep their values in a plain map of the same name
+		it.Kind, it.Keys = kindMap, []string{*key}
+	}
+	if *index >= 0 && it.Kind != kindArray && it.Kind != kindSlice {
+		return fmt.Errorf("-index requires an array or a slice")
+	}
+
+	tf, err := factory(db, *rootFlag)
+	if err != nil {
+		return err
+	}
+	var entries []Entry
+	if *index >= 0 {
+		entries, err = readElem(tf, *it, *index)
+	} else {
+		entries, err = read(tf, *it)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printEntries(w, entries)
+}
+
+func list(db ethdb.Database, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	it := itemFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tf, err := factory(db, *rootFlag)
+	if err != nil {
+		return err
+	}
+	entries, err := read(tf, *it)
+	if err != nil {
+		return err
+	}
+
+	return printEntries(w, entries)
+}
+
+func dump(db ethdb.Database, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	spec := fs.String("spec", "", "JSON file listing the items to read")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	items, err := loadSpec(*spec)
+	if err != nil {
+		return err
+	}
+
+	tf, err := factory(db, *rootFlag)
+	if err != nil {
+		return err
+	}
+
+	entries, err := readAll(tf, items)
+	if err != nil {
+		return err
+	}
+
+	return printEntries(w, entries)
+}
+
+func diffRoots(db ethdb.Database, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	it := itemFlags(fs)
+	against := fs.String("against", "", "state root to compare -root with")
+	spec := fs.String("spec", "", "JSON file listing the items to compare")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	items := []Item{*it}
+	if *spec != "" {
+		var err error
+		if items, err = loadSpec(*spec); err != nil {
+			return err
+		}
+	}
+
+	fromTf, err := factory(db, *rootFlag)
+	if err != nil {
+		return err
+	}
+	toTf, err := factory(db, *against)
+	if err != nil {
+		return err
+	}
+
+	from, err := readAll(fromTf, items)
+	if err != nil {
+		return err
+	}
+	to, err := readAll(toTf, items)
+	if err != nil {
+		return err
+	}
+
+	changes := diff(from, to)
+	if *formatFlag == formatJSON {
+		return printJSON(w, changes)
+	}
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s: %s -> %s\n", c.Path, formatValue(c.From), formatValue(c.To))
+	}
+
+	return nil
+}
+
+func export(db ethdb.Database, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return fmt.Errorf("-out is required")
+	}
+
+	return exportDatabase(db, *out)
+}
+
+func loadSpec(file string) ([]Item, error) {
+	if file == "" {
+		return nil, fmt.Errorf("-spec is required")
+	}
+
+	byts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	if err := json.Unmarshal(byts, &items); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", file, err)
+	}
+
+	return items, nil
+}
+
+func readAll(tf *ethtypes.TypeFactory, items []Item) ([]Entry, error) {
+	var entries []Entry
+	for _, it := range items {
+		es, err := read(tf, it)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, es...)
+	}
+
+	return entries, nil
+}
+
+func printEntries(w io.Writer, entries []Entry) error {
+	if *formatFlag == formatJSON {
+		return printJSON(w, entries)
+	}
+
+	for _, e := range entries {
+		if !e.Assigned {
+			fmt.Fprintf(w, "%s = <unassigned>\n", e.Path)
+			continue
+		}
+		fmt.Fprintf(w, "%s = %s\n", e.Path, formatValue(e.Value))
+	}
+
+	return nil
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// formatValue prints strings quoted and everything else
+// in its JSON form, so that text output is unambiguous.
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<nil>"
+	}
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	byts, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(byts)
+}
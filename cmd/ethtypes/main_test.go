@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+
+	tf.NewString("Owner", "alice")
+	tf.NewStringArray("Array", 3, []string{"a", "b", "c"})
+	m := tf.NewIterableMap("Balances", ethtypes.StringType, ethtypes.BigIntType)
+	m.Set("alice", big.NewInt(10))
+	root1, err := statedb.Commit(false)
+	assert.Nil(t, err)
+
+	tf.NewString("Owner", "bob")
+	m.Set("bob", big.NewInt(20))
+	root2, err := statedb.Commit(false)
+	assert.Nil(t, err)
+	assert.Nil(t, statedb.Database().TrieDB().Commit(root2, false, nil))
+	assert.Nil(t, statedb.Database().TrieDB().Commit(root1, false, nil))
+
+	dir, err := ioutil.TempDir("", "ethtypes")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	memdump := filepath.Join(dir, "db.json")
+	assert.Nil(t, exportDatabase(db, memdump))
+
+	spec := filepath.Join(dir, "spec.json")
+	items := []Item{
+		{Kind: kindVariable, Name: "Owner", Type: "string"},
+		{Kind: kindArray, Name: "Array", Type: "string"},
+		{Kind: kindIterableMap, Name: "Balances", KeyType: "string", Type: "bigint"},
+	}
+	byts, _ := json.Marshal(items)
+	assert.Nil(t, ioutil.WriteFile(spec, byts, 0644))
+
+	*memdumpFlag = memdump
+	*addrFlag = addr.Hex()
+	*rootFlag = root2.Hex()
+
+	var out bytes.Buffer
+	assert.Nil(t, run("dump", []string{"-spec", spec}, &out))
+	assert.Equal(t, `Owner = "bob"
+Array[0] = "a"
+Array[1] = "b"
+Array[2] = "c"
+Balances["alice"] = 10
+Balances["bob"] = 20
+`, out.String())
+
+	out.Reset()
+	assert.Nil(t, run("get", []string{"-kind", "iterablemap", "-name", "Balances", "-keytype", "string", "-type", "bigint", "-key", `"bob"`}, &out))
+	assert.Equal(t, "Balances[\"bob\"] = 20\n", out.String())
+
+	out.Reset()
+	assert.Nil(t, run("get", []string{"-kind", "array", "-name", "Array", "-type", "string", "-index", "1"}, &out))
+	assert.Equal(t, "Array[1] = \"b\"\n", out.String())
+	err = run("get", []string{"-kind", "array", "-name", "Array", "-type", "string", "-index", "3"}, &out)
+	assert.ErrorIs(t, err, ethtypes.ErrIndexOutOfRange)
+	err = run("get", []string{"-kind", "variable", "-name", "Owner", "-type", "string", "-index", "0"}, &out)
+	assert.EqualError(t, err, "-index requires an array or a slice")
+
+	out.Reset()
+	*rootFlag = root1.Hex()
+	assert.Nil(t, run("diff", []string{"-against", root2.Hex(), "-spec", spec}, &out))
+	assert.Equal(t, `Balances["bob"]: <nil> -> 20
+Owner: "alice" -> "bob"
+`, out.String())
+
+	out.Reset()
+	*formatFlag = formatJSON
+	defer func() { *formatFlag = formatText }()
+	assert.Nil(t, run("list", []string{"-kind", "array", "-name", "Array", "-type", "string"}, &out))
+	var entries []Entry
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &entries))
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "Array[2]", entries[2].Path)
+	assert.Equal(t, "c", entries[2].Value)
+}
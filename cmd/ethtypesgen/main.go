@@ -0,0 +1,116 @@
+// Command ethtypesgen generates typed storage bindings. It is meant
+// to be run by go generate.
+//
+// With -type, it generates a XxxStorage wrapper around
+// ethtypes.TypeFactory for a struct whose fields are tagged with
+// `ethtypes` (see package gen for the tag format):
+//
+//	//go:generate go run github.com/TheStarBoys/ethtypes/cmd/ethtypesgen -type Contract
+//
+// With -layout, it binds the variables of a Solidity contract described
+// by the storageLayout JSON of solc (see package layout), naming the
+// result after -type:
+//
+//	//go:generate go run github.com/TheStarBoys/ethtypes/cmd/ethtypesgen -layout Token.json -type Token
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheStarBoys/ethtypes/gen"
+	"github.com/TheStarBoys/ethtypes/layout"
+)
+
+var (
+	typeFlag   = flag.String("type", "", "name of the struct declaring the state, or of the Solidity contract")
+	layoutFlag = flag.String("layout", "", "storageLayout JSON file of a Solidity contract")
+	outputFlag = flag.String("output", "", "output file name; default <type>_storage.go")
+	dirFlag    = flag.String("dir", ".", "directory of the package to generate into")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "ethtypesgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *typeFlag == "" {
+		return fmt.Errorf("-type is required")
+	}
+
+	var (
+		src []byte
+		err error
+	)
+	if *layoutFlag != "" {
+		src, err = generateLayout()
+	} else {
+		src, err = generateStruct()
+	}
+	if err != nil {
+		return err
+	}
+
+	output := *outputFlag
+	if output == "" {
+		output = filepath.Join(*dirFlag, strings.ToLower(*typeFlag)+"_storage.go")
+	}
+
+	return ioutil.WriteFile(output, src, 0644)
+}
+
+func generateStruct() ([]byte, error) {
+	storage, err := gen.Parse(*dirFlag, *typeFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.Generate()
+}
+
+func generateLayout() ([]byte, error) {
+	f, err := os.Open(*layoutFlag)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l, err := layout.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", *layoutFlag, err)
+	}
+
+	pkg, err := packageName(*dirFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Generate(pkg, *typeFlag)
+}
+
+// packageName returns the name of the package in dir.
+func packageName(dir string) (string, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+
+	for name := range pkgs {
+		return name, nil
+	}
+
+	return "", fmt.Errorf("no Go package in %s", dir)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -56,3 +58,135 @@ var (
 	AddressType = reflect.TypeOf(common.Address{})
 	BigIntType  = reflect.TypeOf(big.Int{})
 )
+
+var (
+	// Fixed-size byte arrays, stored in a single slot
+	HashType    = reflect.TypeOf(common.Hash{})
+	Bytes1Type  = reflect.TypeOf([1]byte{})
+	Bytes2Type  = reflect.TypeOf([2]byte{})
+	Bytes3Type  = reflect.TypeOf([3]byte{})
+	Bytes4Type  = reflect.TypeOf([4]byte{})
+	Bytes5Type  = reflect.TypeOf([5]byte{})
+	Bytes6Type  = reflect.TypeOf([6]byte{})
+	Bytes7Type  = reflect.TypeOf([7]byte{})
+	Bytes8Type  = reflect.TypeOf([8]byte{})
+	Bytes9Type  = reflect.TypeOf([9]byte{})
+	Bytes10Type = reflect.TypeOf([10]byte{})
+	Bytes11Type = reflect.TypeOf([11]byte{})
+	Bytes12Type = reflect.TypeOf([12]byte{})
+	Bytes13Type = reflect.TypeOf([13]byte{})
+	Bytes14Type = reflect.TypeOf([14]byte{})
+	Bytes15Type = reflect.TypeOf([15]byte{})
+	Bytes16Type = reflect.TypeOf([16]byte{})
+	Bytes17Type = reflect.TypeOf([17]byte{})
+	Bytes18Type = reflect.TypeOf([18]byte{})
+	Bytes19Type = reflect.TypeOf([19]byte{})
+	Bytes20Type = reflect.TypeOf([20]byte{})
+	Bytes21Type = reflect.TypeOf([21]byte{})
+	Bytes22Type = reflect.TypeOf([22]byte{})
+	Bytes23Type = reflect.TypeOf([23]byte{})
+	Bytes24Type = reflect.TypeOf([24]byte{})
+	Bytes25Type = reflect.TypeOf([25]byte{})
+	Bytes26Type = reflect.TypeOf([26]byte{})
+	Bytes27Type = reflect.TypeOf([27]byte{})
+	Bytes28Type = reflect.TypeOf([28]byte{})
+	Bytes29Type = reflect.TypeOf([29]byte{})
+	Bytes30Type = reflect.TypeOf([30]byte{})
+	Bytes31Type = reflect.TypeOf([31]byte{})
+	Bytes32Type = reflect.TypeOf([32]byte{})
+)
+
+var typeNames = map[string]reflect.Type{
+	"string":  StringType,
+	"int":     IntType,
+	"int8":    Int8Type,
+	"int16":   Int16Type,
+	"int32":   Int32Type,
+	"int64":   Int64Type,
+	"uint":    UintType,
+	"uint8":   Uint8Type,
+	"uint16":  Uint16Type,
+	"uint32":  Uint32Type,
+	"uint64":  Uint64Type,
+	"float32": Float32Type,
+	"float64": Float64Type,
+	"bool":    BoolType,
+	"bytes":   BytesType,
+	"address": AddressType,
+	"bigint":  BigIntType,
+	"hash":    HashType,
+	"decimal": DecimalType,
+	"enum":    EnumType,
+}
+
+func init() {
+	for n := 1; n <= 32; n++ {
+		typeNames[fmt.Sprintf("bytes%d", n)] = reflect.ArrayOf(n, Uint8Type)
+	}
+}
+
+// registeredTypes holds the types added by RegisterType by their names.
+var (
+	registeredTypesMu sync.RWMutex
+	registeredTypes   = map[string]reflect.Type{}
+)
+
+// RegisterType makes ParseType, and so TypeFactory.Open, know typ by
+// TypeName(typ), such as "github.com/acme/app.Person". Register the
+// struct types of stored values on start up. It panics if another
+// type has the same name.
+func RegisterType(typ reflect.Type) {
+	registeredTypesMu.Lock()
+	defer registeredTypesMu.Unlock()
+
+	name := TypeName(typ)
+	if registered, ok := registeredTypes[name]; ok && registered != typ {
+		panic(fmt.Sprintf("type %s registered as %v and %v", name, registered, typ))
+	}
+	registeredTypes[name] = typ
+}
+
+// TypeName returns the name ParseType parses as typ. Named types
+// are qualified by their import path, so they never collide.
+func TypeName(typ reflect.Type) string {
+	for name, t := range typeNames {
+		if t == typ {
+			return name
+		}
+	}
+	if typ.Kind() == reflect.Slice {
+		return "[]" + TypeName(typ.Elem())
+	}
+	if typ.Name() != "" && typ.PkgPath() != "" {
+		return typ.PkgPath() + "." + typ.Name()
+	}
+
+	return typ.String()
+}
+
+// ParseType returns the type called name: one of the basic type names such
+// as "string" or "uint64", "address", "bigint", "bytes", "decimal", "enum",
+// "hash", "bytes1" to "bytes32", a type added by RegisterType, or "[]"
+// followed by one of those.
+func ParseType(name string) (reflect.Type, error) {
+	if strings.HasPrefix(name, "[]") {
+		elem, err := ParseType(name[2:])
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.SliceOf(elem), nil
+	}
+
+	if typ, ok := typeNames[name]; ok {
+		return typ, nil
+	}
+
+	registeredTypesMu.RLock()
+	defer registeredTypesMu.RUnlock()
+	if typ, ok := registeredTypes[name]; ok {
+		return typ, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownType, name)
+}
@@ -0,0 +1,172 @@
+package ethtypes
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+var (
+	big1 = big.NewInt(1)
+	// minInt256 and maxInt256 bound signed counters,
+	// unsigned ones are bounded by 0 and math.MaxBig256
+	minInt256 = new(big.Int).Neg(new(big.Int).Lsh(big1, 255))
+	maxInt256 = new(big.Int).Sub(new(big.Int).Lsh(big1, 255), big1)
+)
+
+// BasicCounter keeps its value in a single slot, located like a
+// variable of the same name. Signed values are two's complement.
+type BasicCounter struct {
+	state  *ContractState
+	name   string
+	loc    common.Hash
+	signed bool
+}
+
+var _ Counter = (*BasicCounter)(nil)
+
+// NewBasicCounter returns the counter called name holding initialVal.
+func NewBasicCounter(state *ContractState, name string, signed bool, initialVal *big.Int) (*BasicCounter, error) {
+	c, err := GetBasicCounter(state, name, signed)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(initialVal)
+
+	return c, nil
+}
+
+func GetBasicCounter(state *ContractState, name string, signed bool) (*BasicCounter, error) {
+	return &BasicCounter{
+		state:  state,
+		name:   name,
+		loc:    state.hash(stateVariablePrefix + name),
+		signed: signed,
+	}, nil
+}
+
+func (c *BasicCounter) Name() string {
+	return c.name
+}
+
+func (c *BasicCounter) Signed() bool {
+	return c.signed
+}
+
+func (c *BasicCounter) Get() *big.Int {
+	v := c.state.getSlot(c.loc).Big()
+	if c.signed {
+		return math.S256(v)
+	}
+
+	return v
+}
+
+// Set panics with ErrOverflow or ErrUnderflow if val is out of range.
+func (c *BasicCounter) Set(val *big.Int) {
+	c.check(val, "set", val)
+	if slot := common.BigToHash(math.U256(new(big.Int).Set(val))); slot != c.state.getSlot(c.loc) {
+		c.state.setSlot(c.loc, slot)
+	}
+}
+
+func (c *BasicCounter) Inc() *big.Int {
+	return c.Add(big1)
+}
+
+func (c *BasicCounter) Dec() *big.Int {
+	return c.Sub(big1)
+}
+
+func (c *BasicCounter) Add(delta *big.Int) *big.Int {
+	val := new(big.Int).Add(c.Get(), delta)
+	c.check(val, "add", delta)
+	c.Set(val)
+
+	return val
+}
+
+func (c *BasicCounter) Sub(delta *big.Int) *big.Int {
+	val := new(big.Int).Sub(c.Get(), delta)
+	c.check(val, "sub", delta)
+	c.Set(val)
+
+	return val
+}
+
+// check panics if val is out of the range of c.
+func (c *BasicCounter) check(val *big.Int, op string, arg *big.Int) {
+	min, max := new(big.Int), math.MaxBig256
+	if c.signed {
+		min, max = minInt256, maxInt256
+	}
+
+	switch {
+	case val.Cmp(max) > 0:
+		panic(fmt.Errorf("%w: %s %s %v", ErrOverflow, op, c.name, arg))
+	case val.Cmp(min) < 0:
+		panic(fmt.Errorf("%w: %s %s %v", ErrUnderflow, op, c.name, arg))
+	}
+}
+
+// BasicMapCounter keeps the counter of each key in a single slot,
+// located like the value of the key in a BasicMap of the same name.
+type BasicMapCounter struct {
+	m *BasicMap
+}
+
+var _ MapCounter = (*BasicMapCounter)(nil)
+
+func NewBasicMapCounter(state *ContractState, name string, keyType reflect.Type) (*BasicMapCounter, error) {
+	return GetBasicMapCounter(state, name, keyType)
+}
+
+func GetBasicMapCounter(state *ContractState, name string, keyType reflect.Type) (*BasicMapCounter, error) {
+	m, err := GetBasicMap(state, name, keyType, BigIntType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BasicMapCounter{m: m}, nil
+}
+
+func (mc *BasicMapCounter) Name() string {
+	return mc.m.Name()
+}
+
+func (mc *BasicMapCounter) KeyType() reflect.Type {
+	return mc.m.keyType
+}
+
+func (mc *BasicMapCounter) Get(key interface{}) *big.Int {
+	return mc.counter(key).Get()
+}
+
+func (mc *BasicMapCounter) Set(key interface{}, val *big.Int) {
+	mc.counter(key).Set(val)
+}
+
+func (mc *BasicMapCounter) Add(key interface{}, delta *big.Int) *big.Int {
+	return mc.counter(key).Add(delta)
+}
+
+func (mc *BasicMapCounter) Sub(key interface{}, delta *big.Int) *big.Int {
+	return mc.counter(key).Sub(delta)
+}
+
+func (mc *BasicMapCounter) counter(key interface{}) *BasicCounter {
+	// getElem checks full types in strict mode
+	if actual, expect := reflect.TypeOf(key).Kind(), mc.m.keyType.Kind(); actual != expect && !mc.m.state.strict {
+		panic(fmt.Sprintf("key not match, actual: %v, expect: %v", actual, expect))
+	}
+	elem := mc.m.getElem(key)
+
+	return &BasicCounter{
+		state: elem.state,
+		name:  fmt.Sprintf("%s[%s]", mc.m.name, encodeKey(key)),
+		loc:   elem.loc,
+	}
+}
@@ -0,0 +1,52 @@
+package ethtypes
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	c := tf.NewCounter("nonce", big.NewInt(0))
+	meter.Reset()
+	assert.Equal(t, big.NewInt(1), c.Inc())
+	// a single slot read and written in place
+	assert.Equal(t, uint64(1), meter.Writes())
+	assert.Equal(t, big.NewInt(11), c.Add(big.NewInt(10)))
+	assert.Equal(t, big.NewInt(10), c.Dec())
+
+	assert.PanicsWithError(t, "underflow: sub nonce 11", func() { c.Sub(big.NewInt(11)) })
+	assert.Equal(t, big.NewInt(10), c.Get())
+	c.Set(math.MaxBig256)
+	assert.Panics(t, func() { c.Inc() })
+
+	i := tf.NewIntCounter("delta", big.NewInt(-1))
+	assert.Equal(t, big.NewInt(-3), i.Sub(big.NewInt(2)))
+	assert.Equal(t, big.NewInt(-3), tf.GetIntCounter("delta").Get())
+	i.Set(minInt256)
+	assert.Panics(t, func() { i.Dec() })
+	assert.Panics(t, func() { tf.GetCounter("delta") })
+
+	votes := tf.NewMapCounter("votes", StringType)
+	assert.Equal(t, 0, votes.Get("alice").Sign())
+	votes.Add("alice", big.NewInt(3))
+	votes.Add("bob", big.NewInt(1))
+	assert.Equal(t, big.NewInt(2), votes.Sub("alice", big.NewInt(1)))
+	assert.Equal(t, big.NewInt(1), votes.Get("bob"))
+	assert.PanicsWithError(t, `underflow: sub votes["bob"] 2`, func() { votes.Sub("bob", big.NewInt(2)) })
+	assert.Panics(t, func() { votes.Add(1, big1) })
+
+	o, err := tf.Open("votes")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), o.(MapCounter).Get("alice"))
+}
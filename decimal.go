@@ -0,0 +1,250 @@
+package ethtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DecimalType is the type of Decimal values, stored in a single slot.
+var DecimalType = reflect.TypeOf(Decimal{})
+
+// decimalBits is the size of the unscaled value in a slot,
+// the first byte of the slot holds the scale.
+const decimalBits = 248
+
+// RoundingMode tells how a Decimal is rounded to fewer digits.
+type RoundingMode int
+
+const (
+	// RoundDown rounds toward zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundHalfUp rounds to the nearest value, halves away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest value, halves to the even one.
+	RoundHalfEven
+)
+
+// Decimal is a fixed-point number: an unscaled big.Int divided by
+// 10^scale. Decimals are immutable, operations return new values.
+// The zero Decimal is 0 with scale 0.
+//
+// Decimals are stored in a single slot, the scale in the first byte and
+// the unscaled value as a 248-bit two's complement integer. In JSON they
+// are strings such as "1.50", and in the ABI the unscaled int256.
+// Zero with scale 0 is the zero slot, which is marked as assigned like a
+// zero common.Hash.
+type Decimal struct {
+	unscaled *big.Int
+	scale    uint8
+}
+
+// NewDecimal returns unscaled / 10^scale.
+func NewDecimal(unscaled *big.Int, scale uint8) Decimal {
+	return Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}
+}
+
+// ParseDecimal parses a decimal such as "-12.345",
+// its scale is the number of digits after the point.
+func ParseDecimal(s string) (Decimal, error) {
+	digits := s
+	var scale int
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		scale = len(s) - i - 1
+		digits = s[:i] + s[i+1:]
+	}
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok || scale > 255 || strings.HasSuffix(s, ".") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	return Decimal{unscaled: unscaled, scale: uint8(scale)}, nil
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Unscaled returns d * 10^scale.
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.int())
+}
+
+// Scale returns the number of digits after the point.
+func (d Decimal) Scale() uint8 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 like big.Int.Sign.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Cmp compares d and y like big.Int.Cmp, regardless of their scales.
+func (d Decimal) Cmp(y Decimal) int {
+	a, b := align(d, y)
+	return a.Cmp(b)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Add returns d + y with the larger scale of both.
+func (d Decimal) Add(y Decimal) Decimal {
+	a, b := align(d, y)
+	return Decimal{unscaled: a.Add(a, b), scale: maxScale(d, y)}
+}
+
+// Sub returns d - y with the larger scale of both.
+func (d Decimal) Sub(y Decimal) Decimal {
+	a, b := align(d, y)
+	return Decimal{unscaled: a.Sub(a, b), scale: maxScale(d, y)}
+}
+
+// Mul returns d * y with the scale of d, rounded by mode.
+func (d Decimal) Mul(y Decimal, mode RoundingMode) Decimal {
+	product := new(big.Int).Mul(d.int(), y.int())
+	return Decimal{unscaled: quoRound(product, pow10(y.scale), mode), scale: d.scale}
+}
+
+// Quo returns d / y with the scale of d, rounded by mode.
+// It panics if y is zero.
+func (d Decimal) Quo(y Decimal, mode RoundingMode) Decimal {
+	if y.Sign() == 0 {
+		panic("division by zero")
+	}
+
+	n := new(big.Int).Mul(d.int(), pow10(y.scale))
+	return Decimal{unscaled: quoRound(n, y.int(), mode), scale: d.scale}
+}
+
+// Rescale returns d with scale digits after the point, rounded by mode.
+func (d Decimal) Rescale(scale uint8, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		unscaled := new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+		return Decimal{unscaled: unscaled, scale: scale}
+	}
+
+	return Decimal{unscaled: quoRound(d.int(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(d.scale)] + "." + digits[len(digits)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a string or a number.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+
+	return nil
+}
+
+// slot returns the stored form of d, it panics if the
+// unscaled value does not fit into 248 bits.
+func (d Decimal) slot() common.Hash {
+	v := d.int()
+	limit := new(big.Int).Lsh(big.NewInt(1), decimalBits-1)
+	if v.Cmp(limit) >= 0 || v.Cmp(new(big.Int).Neg(limit)) < 0 {
+		panic(fmt.Sprintf("decimal %v overflows %d bits", d, decimalBits))
+	}
+	if v.Sign() < 0 {
+		v = new(big.Int).Add(v, new(big.Int).Lsh(limit, 1))
+	}
+
+	slot := common.BigToHash(v)
+	slot[0] = d.scale
+	return slot
+}
+
+func decimalFromSlot(slot common.Hash) Decimal {
+	scale := slot[0]
+	slot[0] = 0
+	v := slot.Big()
+	if v.Bit(decimalBits-1) == 1 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), decimalBits))
+	}
+
+	return Decimal{unscaled: v, scale: scale}
+}
+
+// align returns the unscaled values of x and y at the larger scale.
+func align(x, y Decimal) (*big.Int, *big.Int) {
+	scale := maxScale(x, y)
+	a := new(big.Int).Mul(x.int(), pow10(scale-x.scale))
+	b := new(big.Int).Mul(y.int(), pow10(scale-y.scale))
+	return a, b
+}
+
+func maxScale(x, y Decimal) uint8 {
+	if x.scale > y.scale {
+		return x.scale
+	}
+	return y.scale
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// quoRound returns n / d rounded by mode.
+func quoRound(n, d *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// the sign of the exact quotient
+	sign := big.NewInt(int64(n.Sign() * d.Sign()))
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(new(big.Int).Abs(d))
+	switch mode {
+	case RoundUp:
+		q.Add(q, sign)
+	case RoundHalfUp:
+		if cmp >= 0 {
+			q.Add(q, sign)
+		}
+	case RoundHalfEven:
+		if cmp > 0 || cmp == 0 && q.Bit(0) == 1 {
+			q.Add(q, sign)
+		}
+	}
+
+	return q
+}
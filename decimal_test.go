@@ -0,0 +1,127 @@
+package ethtypes
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestDecimal(t *testing.T) {
+	for _, s := range []string{"0", "1.50", "-0.05", "123456.789"} {
+		assert.Equal(t, s, mustDecimal(s).String())
+	}
+	for _, s := range []string{"", "1.", "1.2.3", "1e5", "abc"} {
+		_, err := ParseDecimal(s)
+		assert.Error(t, err, s)
+	}
+
+	a, b := mustDecimal("1.25"), mustDecimal("0.5")
+	assert.Equal(t, "1.75", a.Add(b).String())
+	assert.Equal(t, "0.75", a.Sub(b).String())
+	assert.Equal(t, "0.62", a.Mul(b, RoundDown).String())
+	assert.Equal(t, "0.63", a.Mul(b, RoundHalfUp).String())
+	assert.Equal(t, "0.62", a.Mul(b, RoundHalfEven).String())
+	assert.Equal(t, "2.50", a.Quo(b, RoundDown).String())
+	assert.Equal(t, "0.33", mustDecimal("1.00").Quo(mustDecimal("3"), RoundDown).String())
+	assert.Equal(t, "0.34", mustDecimal("1.00").Quo(mustDecimal("3"), RoundUp).String())
+	assert.Equal(t, "-1.3", mustDecimal("-1.25").Rescale(1, RoundHalfUp).String())
+	assert.Equal(t, "-1.2", mustDecimal("-1.25").Rescale(1, RoundHalfEven).String())
+	assert.Equal(t, "1.2500", a.Rescale(4, RoundDown).String())
+	assert.Equal(t, 0, mustDecimal("1.5").Cmp(mustDecimal("1.500")))
+	assert.True(t, NaturalLess(b, a))
+	assert.Panics(t, func() { a.Quo(Decimal{}, RoundDown) })
+
+	type Order struct {
+		Price Decimal
+	}
+	byts, err := json.Marshal(Order{Price: a})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Price":"1.25"}`, string(byts))
+	var order Order
+	assert.NoError(t, json.Unmarshal(byts, &order))
+	assert.Equal(t, 0, a.Cmp(order.Price))
+}
+
+func TestDecimalStorage(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	v := tf.NewVariable("price", mustDecimal("-12.345"))
+	meter.Reset()
+	v.Set(mustDecimal("0.001"))
+	assert.Equal(t, uint64(1), meter.Writes())
+
+	prices := tf.NewMap("prices", StringType, DecimalType)
+	prices.Set("eth", mustDecimal("-12.345"))
+	var got Decimal
+	assert.True(t, prices.Get("eth", &got))
+	assert.Equal(t, "-12.345", got.String())
+	assert.Equal(t, uint8(3), got.Scale())
+
+	// zero with scale 0 is the zero slot, but still assigned
+	prices.Set("zero", Decimal{})
+	assert.True(t, prices.Contains("zero"))
+	assert.True(t, prices.Get("zero", &got))
+	assert.Equal(t, "0", got.String())
+	prices.Set("zero", mustDecimal("0.00"))
+	assert.True(t, prices.Get("zero", &got))
+	assert.Equal(t, "0.00", got.String())
+	prices.Set("zero", mustDecimal("0"))
+	assert.True(t, prices.Contains("zero"))
+	prices.Del("zero")
+	assert.False(t, prices.Contains("zero"))
+
+	balances := tf.NewIterableMap("balances", AddressType, DecimalType)
+	for i := 0; i < 3; i++ {
+		balances.Set(common.HexToAddress("1"), Decimal{})
+	}
+	assert.Equal(t, 1, balances.Len())
+	assert.True(t, balances.Get(common.HexToAddress("1"), &got))
+	balances.Del(common.HexToAddress("1"))
+	assert.Equal(t, 0, balances.Len())
+
+	max := NewDecimal(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 247), big.NewInt(1)), 0)
+	v.Set(max)
+	v.Get(&got)
+	assert.Equal(t, 0, max.Cmp(got))
+	assert.Panics(t, func() { v.Set(max.Add(mustDecimal("1"))) })
+
+	noFloats, _ := NewTypeFactory(state, common.HexToAddress("456"), WithoutFloats())
+	type Position struct {
+		Size float64
+	}
+	assert.Panics(t, func() { noFloats.NewArray("sizes", 1, Float64Type) })
+	assert.Panics(t, func() { noFloats.GetMap("positions", StringType, reflect.TypeOf(Position{})) })
+	assert.NotPanics(t, func() { noFloats.NewVariable("price", Decimal{}) })
+
+	// rejected constructors write nothing
+	assert.Panics(t, func() { noFloats.NewFloat64("f", 1.5) })
+	assert.Panics(t, func() { noFloats.NewVariable("pos", Position{Size: 1}) })
+	assert.Panics(t, func() { noFloats.NewSlice("sizes", 2, 2, Float64Type) })
+	assert.Panics(t, func() { noFloats.NewIterableMap("positions", StringType, reflect.TypeOf(Position{})) })
+	tf, _ = NewTypeFactory(state, common.HexToAddress("456"))
+	assert.False(t, tf.GetVariable("f", Float64Type).IsAssigned())
+	assert.False(t, tf.GetVariable("pos", reflect.TypeOf(Position{})).IsAssigned())
+	assert.Equal(t, 0, tf.GetSlice("sizes", 0, 0, Float64Type).Len())
+	assert.Equal(t, 0, tf.GetIterableMap("positions", StringType, reflect.TypeOf(Position{})).Len())
+	for _, name := range []string{"f", "pos", "sizes", "positions"} {
+		_, err := tf.Open(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,241 @@
+package ethtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const descriptorPrefix = "meta_"
+
+// Kinds of descriptors.
+const (
+	KindVariable    = "variable"
+	KindArray       = "array"
+	KindSlice       = "slice"
+	KindMap         = "map"
+	KindIterableMap = "iterablemap"
+	KindBitmap      = "bitmap"
+	KindCounter     = "counter"
+	KindIntCounter  = "intcounter"
+	KindMapCounter  = "mapcounter"
+)
+
+// descriptor is persisted for every variable and container a TypeFactory
+// creates. Types are stored by TypeName, and elements of EnumType also
+// store the name of their Enum.
+type descriptor struct {
+	Kind      string `json:"kind"`
+	Key       string `json:"key,omitempty"`
+	Elem      string `json:"elem"`
+	Enum      string `json:"enum,omitempty"`
+	Committed bool   `json:"committed,omitempty"`
+}
+
+func (d *descriptor) String() string {
+	s := d.Kind
+	if d.Committed {
+		s = "committed " + s
+	}
+	elem := d.Elem
+	if d.Enum != "" {
+		elem += " " + d.Enum
+	}
+	if d.Key != "" {
+		return fmt.Sprintf("%s of %s to %s", s, d.Key, elem)
+	}
+
+	return fmt.Sprintf("%s of %s", s, elem)
+}
+
+func newDescriptor(kind string, keyType, elemType reflect.Type, committed bool) *descriptor {
+	d := &descriptor{
+		Kind:      kind,
+		Committed: committed,
+	}
+	if elemType != nil {
+		d.Elem = TypeName(derefType(elemType))
+	}
+	if keyType != nil {
+		d.Key = TypeName(derefType(keyType))
+	}
+
+	return d
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	for count := 3; count > 0 && typ.Kind() == reflect.Ptr; count-- {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+func (t *TypeFactory) descriptorLoc(name string) common.Hash {
+	return t.state.hash(descriptorPrefix + name)
+}
+
+// describe persists the descriptor of name, names are shared by all kinds
+// so it replaces the descriptor of a previous variable or container.
+// Constructors check floats before they write anything, not here.
+func (t *TypeFactory) describe(name, kind string, keyType, elemType reflect.Type, committed bool) {
+	t.persist(name, newDescriptor(kind, keyType, elemType, committed))
+}
+
+func (t *TypeFactory) persist(name string, d *descriptor) {
+	byts, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+
+	t.state.Write(t.descriptorLoc(name), byts)
+}
+
+// descriptor returns the descriptor of name, which is nil
+// for data created before descriptors were persisted.
+func (t *TypeFactory) descriptor(name string) (*descriptor, error) {
+	byts := t.state.Read(t.descriptorLoc(name))
+	if len(byts) == 0 {
+		return nil, nil
+	}
+
+	var d descriptor
+	if err := json.Unmarshal(byts, &d); err != nil {
+		return nil, fmt.Errorf("decode descriptor of %s: %w", name, err)
+	}
+
+	return &d, nil
+}
+
+// Kind returns the kind name was created as, such as KindMap, and whether
+// it is committed. The kind is empty if name has no descriptor.
+func (t *TypeFactory) Kind(name string) (kind string, committed bool, err error) {
+	d, err := t.descriptor(name)
+	if err != nil || d == nil {
+		return "", false, err
+	}
+
+	return d.Kind, d.Committed, nil
+}
+
+// check panics if name is described as anything but the given kind and
+// types. The stored element type may widen to the requested one.
+func (t *TypeFactory) check(name, kind string, keyType, elemType reflect.Type, committed bool) {
+	t.checkFloats(name, keyType, elemType)
+	t.expect(name, newDescriptor(kind, keyType, elemType, committed), elemType)
+}
+
+// expect panics if name is described as anything but expect,
+// whose element type is elemType.
+func (t *TypeFactory) expect(name string, expect *descriptor, elemType reflect.Type) {
+	d, err := t.descriptor(name)
+	if err != nil {
+		panic(err)
+	}
+	if d == nil {
+		return
+	}
+
+	sameKind := d.Kind == expect.Kind && d.Committed == expect.Committed
+	// reading an iterable map as a map cannot break its list of keys
+	if t.state.readOnly && d.Kind == KindIterableMap && expect.Kind == KindMap {
+		sameKind = true
+	}
+	// and reading members without their enum cannot store foreign ones
+	sameEnum := d.Enum == expect.Enum || t.state.readOnly && expect.Enum == ""
+	if sameKind && d.Key == expect.Key && sameEnum {
+		if d.Elem == expect.Elem {
+			return
+		}
+		if stored, err := ParseType(d.Elem); err == nil && elemType != nil && Widens(stored, derefType(elemType)) {
+			return
+		}
+	}
+
+	panic(fmt.Errorf("%w: %s is a %v, not a %v", ErrTypeMismatch, name, d, expect))
+}
+
+// Open returns the variable or container called name as created, without
+// knowing its type: a StateVariable, Array, Slice, CommittedSlice, Map,
+// IterableMap, CommittedIterableMap, Bitmap, Counter or MapCounter. Struct
+// types must have been added by RegisterType, and members of an enum are
+// checked against it. It returns ErrNotFound if name has no descriptor.
+func (t *TypeFactory) Open(name string) (interface{}, error) {
+	d, err := t.descriptor(name)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	c, err := t.open(name, d)
+	if err != nil || d.Enum == "" {
+		return c, err
+	}
+	values := t.enumValues(d.Enum)
+	if values == nil {
+		return nil, fmt.Errorf("%w: enum %s of %s", ErrNotFound, d.Enum, name)
+	}
+	setEnum(c, &Enum{tf: t, name: d.Enum, values: values})
+
+	return c, nil
+}
+
+func (t *TypeFactory) open(name string, d *descriptor) (interface{}, error) {
+	elemType, err := ParseType(d.Elem)
+	if err != nil {
+		return nil, err
+	}
+	var keyType reflect.Type
+	if d.Key != "" {
+		if keyType, err = ParseType(d.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	switch d.Kind {
+	case KindVariable:
+		return GetBasicStateVariable(t.state, name, elemType)
+	case KindArray:
+		return GetBasicArray(t.state, name, elemType)
+	case KindSlice:
+		slice, err := GetBasicSlice(t.state, name, elemType)
+		if err == nil && d.Committed {
+			slice.arr.commit()
+		}
+		return slice, err
+	case KindMap:
+		return GetBasicMap(t.state, name, keyType, elemType)
+	case KindIterableMap:
+		m, err := GetBasicIterableMap(t.state, name, keyType, elemType)
+		if err == nil && d.Committed {
+			m.commit()
+		}
+		return m, err
+	case KindBitmap:
+		return GetBasicBitmap(t.state, name)
+	case KindCounter, KindIntCounter:
+		return GetBasicCounter(t.state, name, d.Kind == KindIntCounter)
+	case KindMapCounter:
+		return GetBasicMapCounter(t.state, name, keyType)
+	}
+
+	return nil, fmt.Errorf("%w: kind %s of %s", ErrUnknownType, d.Kind, name)
+}
+
+// retype replaces the element type in the descriptor of name, if any.
+func (t *TypeFactory) retype(name string, elemType reflect.Type) error {
+	d, err := t.descriptor(name)
+	if err != nil || d == nil {
+		return err
+	}
+
+	d.Elem = TypeName(derefType(elemType))
+	d.Enum = ""
+	t.persist(name, d)
+
+	return nil
+}
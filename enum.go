@@ -0,0 +1,327 @@
+package ethtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const enumPrefix = "enum_"
+
+// EnumType is the type of EnumValue, stored in a single slot as the index
+// of the member plus one, so a variable holding the first member is
+// assigned.
+var EnumType = reflect.TypeOf(EnumValue{})
+
+// Enum is a list of named members, registered by TypeFactory.NewEnum.
+// Its variables and containers only accept its members.
+type Enum struct {
+	tf     *TypeFactory
+	name   string
+	values []string
+}
+
+// EnumValue is a member of an Enum, it prints as the name of the member.
+type EnumValue struct {
+	enum  *Enum
+	index uint8
+}
+
+// NewEnum registers the enum called name with the members values, at most
+// 256 unique non-empty names. The members are persisted: registering the
+// enum again may only append members, since the stored values of existing
+// members must keep their meaning.
+func (t *TypeFactory) NewEnum(name string, values ...string) *Enum {
+	if len(values) == 0 || len(values) > 256 {
+		panic(fmt.Sprintf("enum %s has %d members, expect 1 to 256", name, len(values)))
+	}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if v == "" || seen[v] {
+			panic(fmt.Sprintf("enum %s has empty or duplicate member %q", name, v))
+		}
+		seen[v] = true
+	}
+
+	if stored := t.enumValues(name); stored != nil {
+		if len(stored) > len(values) || !reflect.DeepEqual(stored, values[:len(stored)]) {
+			panic(fmt.Errorf("%w: enum %s has members %v, not %v", ErrTypeMismatch, name, stored, values))
+		}
+	}
+	byts, err := json.Marshal(values)
+	if err != nil {
+		panic(err)
+	}
+	t.state.Write(t.enumLoc(name), byts)
+
+	return &Enum{tf: t, name: name, values: values}
+}
+
+// GetEnum returns the enum called name registered by NewEnum,
+// it panics with ErrNotFound if there is none.
+func (t *TypeFactory) GetEnum(name string) *Enum {
+	values := t.enumValues(name)
+	if values == nil {
+		panic(fmt.Errorf("%w: enum %s", ErrNotFound, name))
+	}
+
+	return &Enum{tf: t, name: name, values: values}
+}
+
+func (t *TypeFactory) enumLoc(name string) common.Hash {
+	return t.state.hash(enumPrefix + name)
+}
+
+func (t *TypeFactory) enumValues(name string) []string {
+	byts := t.state.Read(t.enumLoc(name))
+	if len(byts) == 0 {
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(byts, &values); err != nil {
+		panic(fmt.Errorf("decode enum %s: %w", name, err))
+	}
+
+	return values
+}
+
+func (e *Enum) Name() string {
+	return e.name
+}
+
+// Values returns the names of the members in order.
+func (e *Enum) Values() []string {
+	return append([]string(nil), e.values...)
+}
+
+// Value returns the member called name.
+func (e *Enum) Value(name string) (EnumValue, error) {
+	for i, v := range e.values {
+		if v == name {
+			return EnumValue{enum: e, index: uint8(i)}, nil
+		}
+	}
+
+	return EnumValue{}, fmt.Errorf("%w: %s of enum %s", ErrNotMember, name, e.name)
+}
+
+// MustValue is like Value but panics if there is no member called name.
+func (e *Enum) MustValue(name string) EnumValue {
+	v, err := e.Value(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// same reports whether e and o are the same enum, which may have
+// been registered again with appended members.
+func (e *Enum) same(o *Enum) bool {
+	if e == o {
+		return true
+	}
+	if e.name != o.name || e.tf.state.ns != o.tf.state.ns {
+		return false
+	}
+	n := len(e.values)
+	if len(o.values) < n {
+		n = len(o.values)
+	}
+
+	return reflect.DeepEqual(e.values[:n], o.values[:n])
+}
+
+// check panics with ErrNotMember unless v is a member of e.
+func (e *Enum) check(name string, v EnumValue) {
+	if v.enum == nil || (e != nil && (!e.same(v.enum) || int(v.index) >= len(e.values))) {
+		panic(fmt.Errorf("%w: %v of %s", ErrNotMember, v, name))
+	}
+}
+
+// Enum returns the enum of v, which is nil for a value
+// read without it, such as one decoded from JSON.
+func (v EnumValue) Enum() *Enum {
+	return v.enum
+}
+
+// Index returns the stored form of v.
+func (v EnumValue) Index() uint8 {
+	return v.index
+}
+
+// String returns the name of the member, or its index
+// if v was read without its enum.
+func (v EnumValue) String() string {
+	if v.enum == nil || int(v.index) >= len(v.enum.values) {
+		return strconv.Itoa(int(v.index))
+	}
+
+	return v.enum.values[v.index]
+}
+
+// MarshalJSON encodes v as its index, like it is stored.
+func (v EnumValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.index)
+}
+
+func (v *EnumValue) UnmarshalJSON(data []byte) error {
+	*v = EnumValue{}
+	return json.Unmarshal(data, &v.index)
+}
+
+// describe persists the descriptor of name as holding members of e.
+func (e *Enum) describe(name, kind string, keyType reflect.Type) {
+	d := newDescriptor(kind, keyType, EnumType, false)
+	d.Enum = e.name
+	e.tf.persist(name, d)
+}
+
+// expect panics if name is described as anything but holding members of e.
+func (e *Enum) expect(name, kind string, keyType reflect.Type) {
+	e.tf.checkFloats(name, keyType)
+	d := newDescriptor(kind, keyType, EnumType, false)
+	d.Enum = e.name
+	e.tf.expect(name, d, EnumType)
+}
+
+// setEnum makes the variable or container c only accept members of e.
+func setEnum(c interface{}, e *Enum) {
+	switch c := c.(type) {
+	case *BasicStateVariable:
+		c.enum = e
+	case *BasicArray:
+		c.enum = e
+	case *BasicSlice:
+		c.arr.enum = e
+	case *BasicMap:
+		c.enum = e
+	case *BasicIterableMap:
+		c.data.enum = e
+	}
+}
+
+// NewVariable returns the variable called name holding members of e.
+func (e *Enum) NewVariable(name string, initialVal EnumValue) StateVariable {
+	v, err := GetBasicStateVariable(e.tf.state, name, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	v.enum = e
+	v.Set(initialVal)
+	e.describe(name, KindVariable, nil)
+
+	return v
+}
+
+// GetVariable gets the variable called name, it panics
+// unless name was created as a variable of e.
+func (e *Enum) GetVariable(name string) StateVariable {
+	v, err := GetBasicStateVariable(e.tf.state, name, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	v.enum = e
+	e.expect(name, KindVariable, nil)
+
+	return v
+}
+
+// NewArray returns the array called name whose elements are members of e.
+func (e *Enum) NewArray(name string, length int) Array {
+	arr, err := NewBasicArray(e.tf.state, name, length, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	arr.enum = e
+	e.describe(name, KindArray, nil)
+
+	return arr
+}
+
+func (e *Enum) GetArray(name string) Array {
+	arr, err := GetBasicArray(e.tf.state, name, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	arr.enum = e
+	e.expect(name, KindArray, nil)
+
+	return arr
+}
+
+// NewSlice returns the slice called name whose elements are members of e.
+func (e *Enum) NewSlice(name string, length, cap int) Slice {
+	slice, err := NewBasicSlice(e.tf.state, name, length, cap, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	slice.arr.enum = e
+	e.describe(name, KindSlice, nil)
+
+	return slice
+}
+
+func (e *Enum) GetSlice(name string) Slice {
+	slice, err := GetBasicSlice(e.tf.state, name, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	slice.arr.enum = e
+	e.expect(name, KindSlice, nil)
+
+	return slice
+}
+
+// NewMap returns the map called name whose values are members of e.
+func (e *Enum) NewMap(name string, keyType reflect.Type) Map {
+	e.tf.checkFloats(name, keyType)
+	m, err := NewBasicMap(e.tf.state, name, keyType, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	m.enum = e
+	e.describe(name, KindMap, keyType)
+
+	return m
+}
+
+func (e *Enum) GetMap(name string, keyType reflect.Type) Map {
+	m, err := GetBasicMap(e.tf.state, name, keyType, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	m.enum = e
+	e.expect(name, KindMap, keyType)
+
+	return m
+}
+
+// NewIterableMap returns the iterable map called name
+// whose values are members of e.
+func (e *Enum) NewIterableMap(name string, keyType reflect.Type) IterableMap {
+	e.tf.checkFloats(name, keyType)
+	m, err := NewBasicIterableMap(e.tf.state, name, keyType, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	m.data.enum = e
+	e.describe(name, KindIterableMap, keyType)
+
+	return m
+}
+
+func (e *Enum) GetIterableMap(name string, keyType reflect.Type) IterableMap {
+	m, err := GetBasicIterableMap(e.tf.state, name, keyType, EnumType)
+	if err != nil {
+		panic(err)
+	}
+	m.data.enum = e
+	e.expect(name, KindIterableMap, keyType)
+
+	return m
+}
@@ -0,0 +1,99 @@
+package ethtypes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnum(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	tf, _ := NewTypeFactory(state, addr)
+
+	status := tf.NewEnum("Status", "Pending", "Active", "Slashed")
+	active := status.MustValue("Active")
+	_, err := status.Value("Unknown")
+	assert.ErrorIs(t, err, ErrNotMember)
+
+	v := status.NewVariable("status", active)
+	// stored as the index plus one
+	assert.Equal(t, common.Hash{31: 2}, state.GetState(addr, v.Addr()))
+	assert.Equal(t, fmt.Sprintf("status 0x%x => Active", v.Addr()), VariableToStr(v))
+
+	// only members of the enum are accepted
+	assert.Panics(t, func() { v.Set(1) })
+	assert.Panics(t, func() { v.Set(EnumValue{}) })
+	other := tf.NewEnum("Color", "Red", "Green")
+	assert.Panics(t, func() { v.Set(other.MustValue("Green")) })
+
+	validators := status.NewMap("validators", AddressType)
+	validators.Set(addr, status.MustValue("Slashed"))
+	var got EnumValue
+	validators.Get(addr, &got)
+	assert.Equal(t, "Slashed", got.String())
+	assert.Equal(t, uint8(2), got.Index())
+
+	history := status.NewSlice("history", 0, 0)
+	history.Append(active)
+	assert.Panics(t, func() { history.Append(other.MustValue("Red")) })
+	assert.Equal(t, "history len: 1, cap: 2 history len: 1 [Active]", SliceToStr(history))
+
+	// members may only be appended
+	assert.NotPanics(t, func() { tf.NewEnum("Status", "Pending", "Active", "Slashed", "Exited") })
+	assert.Panics(t, func() { tf.NewEnum("Status", "Active", "Pending") })
+	assert.Panics(t, func() { tf.NewEnum("Empty") })
+	assert.Panics(t, func() { tf.NewEnum("Twice", "A", "A") })
+
+	status = tf.GetEnum("Status")
+	assert.Equal(t, []string{"Pending", "Active", "Slashed", "Exited"}, status.Values())
+	status.GetVariable("status").Get(&got)
+	assert.Equal(t, "Active", got.String())
+	status.GetVariable("status").Set(status.MustValue("Exited"))
+	assert.Panics(t, func() { tf.GetEnum("Missing") })
+
+	// containers keep the name of their enum
+	color := tf.NewEnum("Color", "Red", "Green", "Blue", "Black")
+	assert.Panics(t, func() { tf.GetMap("validators", AddressType, EnumType) })
+	assert.Panics(t, func() { color.GetMap("validators", AddressType) })
+	c, err := tf.Open("validators")
+	assert.NoError(t, err)
+	assert.Panics(t, func() { c.(Map).Set(addr, color.MustValue("Black")) })
+	c.(Map).Get(addr, &got)
+	assert.Equal(t, "Slashed", got.String())
+
+	ro, _ := NewReadOnlyTypeFactory(state, addr)
+	ro.GetMap("validators", AddressType, EnumType).Get(addr, &got)
+	assert.Equal(t, uint8(2), got.Index())
+}
+
+func TestEnumFirstMember(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	tf, _ := NewTypeFactory(state, addr)
+
+	e := tf.NewEnum("Letter", "A", "B")
+	first := e.MustValue("A")
+	v := e.NewVariable("letter", first)
+	assert.True(t, v.IsAssigned())
+	assert.Equal(t, common.Hash{31: 1}, state.GetState(addr, v.Addr()))
+
+	em := e.NewIterableMap("letters", AddressType)
+	em.Set(addr, first)
+	em.Set(addr, first)
+	assert.Equal(t, 1, em.Len())
+	var got EnumValue
+	assert.True(t, em.Get(addr, &got))
+	assert.Equal(t, "A", got.String())
+	assert.True(t, em.Contains(addr))
+
+	em.Del(addr)
+	assert.Equal(t, 0, em.Len())
+	assert.False(t, em.Contains(addr))
+}
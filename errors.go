@@ -4,4 +4,20 @@ import "errors"
 
 var (
 	ErrIndexOutOfRange = errors.New("index out of range")
+	ErrUnknownType     = errors.New("unknown type")
+	ErrKindMismatch    = errors.New("kind not match")
+
+	ErrConcurrentModification = errors.New("container modified during iteration")
+	ErrNotCommitted           = errors.New("container is not committed")
+	ErrKeyNotFound            = errors.New("key not found")
+	ErrInvalidProof           = errors.New("invalid proof")
+	ErrReadOnly               = errors.New("write to read-only state")
+	ErrNoMigration            = errors.New("no migration")
+	ErrTypeMismatch           = errors.New("type not match")
+	ErrNotFound               = errors.New("not found")
+	ErrFloatType              = errors.New("float type not allowed")
+	ErrNotMember              = errors.New("not an enum member")
+	ErrOverflow               = errors.New("overflow")
+	ErrUnderflow              = errors.New("underflow")
+	ErrGasLimit               = errors.New("gas limit exceeded")
 )
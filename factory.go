@@ -1,28 +1,99 @@
 package ethtypes
 
 import (
+	"fmt"
+	"math/big"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 )
 
 type TypeFactory struct {
 	state *ContractState
+	// noFloats rejects float types, see WithoutFloats
+	noFloats bool
+	// migrations[from] upgrades the schema from version from
+	migrations map[uint64]migration
 }
 
-func NewTypeFactory(db vm.StateDB, contractAddr common.Address) (*TypeFactory, error) {
+// Option configures a TypeFactory.
+type Option func(*TypeFactory)
+
+// WithStrictTypes makes variables and containers check full types
+// instead of kinds. Set and map keys only accept values whose type
+// Widens to the declared type, and convert them; Get only decodes into
+// a type the declared type Widens to, and fails if the stored value
+// cannot be decoded. Failed checks panic with a *TypeError.
+func WithStrictTypes() Option {
+	return func(t *TypeFactory) {
+		t.state.strict = true
+	}
+}
+
+// WithoutFloats makes New* and Get* panic with ErrFloatType for value
+// and key types holding float32 or float64, including in struct fields
+// and slice elements. Use Decimal for fractional amounts instead.
+func WithoutFloats() Option {
+	return func(t *TypeFactory) {
+		t.noFloats = true
+	}
+}
+
+func NewTypeFactory(db vm.StateDB, contractAddr common.Address, opts ...Option) (*TypeFactory, error) {
 	state := NewContractState(db, contractAddr)
-	return &TypeFactory{
+	tf := &TypeFactory{
 		state: state,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(tf)
+	}
+
+	return tf, nil
+}
+
+// NewReadOnlyTypeFactory returns a TypeFactory whose variables and
+// containers only read db, every write panics with ErrReadOnly.
+func NewReadOnlyTypeFactory(db vm.StateDB, contractAddr common.Address, opts ...Option) (*TypeFactory, error) {
+	tf, err := NewTypeFactory(db, contractAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tf.state.readOnly = true
+
+	return tf, nil
+}
+
+// OpenReadOnlyTypeFactory opens the state at root, such as the state
+// root of a past block, and returns a read-only TypeFactory over it.
+func OpenReadOnlyTypeFactory(root common.Hash, db state.Database, contractAddr common.Address, opts ...Option) (*TypeFactory, error) {
+	stateDB, err := state.New(root, db, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open state %v: %w", root, err)
+	}
+
+	return NewReadOnlyTypeFactory(stateDB, contractAddr, opts...)
+}
+
+// Namespace returns a TypeFactory of the same contract whose names are
+// hashed under the namespace called name, so they never collide with the
+// names of t or of other namespaces. Namespaces can be nested.
+func (t *TypeFactory) Namespace(name string) *TypeFactory {
+	child := *t
+	child.state = t.state.namespace(name)
+	child.migrations = nil
+	return &child
 }
 
 func (t *TypeFactory) NewVariable(name string, initialVal interface{}) StateVariable {
+	t.checkFloats(name, reflect.TypeOf(initialVal))
 	v, err := NewBasicStateVariable(t.state, name, initialVal)
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindVariable, nil, v.Type(), false)
 
 	return v
 }
@@ -32,6 +103,7 @@ func (t *TypeFactory) GetVariable(name string, typ reflect.Type) StateVariable {
 	if err != nil {
 		panic(err)
 	}
+	t.check(name, KindVariable, nil, typ, false)
 
 	return v
 }
@@ -41,6 +113,7 @@ func (t *TypeFactory) NewString(name, initialVal string) StateVariable {
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindVariable, nil, v.Type(), false)
 
 	return v
 }
@@ -50,6 +123,7 @@ func (t *TypeFactory) NewInt(name string, initialVal int) StateVariable {
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindVariable, nil, v.Type(), false)
 
 	return v
 }
@@ -59,33 +133,41 @@ func (t *TypeFactory) NewUint(name string, initialVal uint) StateVariable {
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindVariable, nil, v.Type(), false)
 
 	return v
 }
 
 func (t *TypeFactory) NewFloat64(name string, initialVal float64) StateVariable {
+	t.checkFloats(name, Float64Type)
 	v, err := NewBasicStateVariable(t.state, name, initialVal)
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindVariable, nil, v.Type(), false)
 
 	return v
 }
 
 func (t *TypeFactory) NewArray(name string, length int, typ reflect.Type) Array {
+	t.checkFloats(name, typ)
 	arr, err := NewBasicArray(t.state, name, length, typ)
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindArray, nil, typ, false)
 
 	return arr
 }
 
+// GetArray gets the array called name, length is ignored. It panics if
+// name was created as anything but an array of typ.
 func (t *TypeFactory) GetArray(name string, length int, typ reflect.Type) Array {
 	arr, err := GetBasicArray(t.state, name, typ)
 	if err != nil {
 		panic(err)
 	}
+	t.check(name, KindArray, nil, typ, false)
 
 	return arr
 }
@@ -99,6 +181,7 @@ func (t *TypeFactory) NewStringArray(name string, length int, initialData []stri
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindArray, nil, StringType, false)
 
 	for i, v := range initialData {
 		arr.Set(i, v)
@@ -107,19 +190,24 @@ func (t *TypeFactory) NewStringArray(name string, length int, initialData []stri
 }
 
 func (t *TypeFactory) NewSlice(name string, length, cap int, typ reflect.Type) Slice {
+	t.checkFloats(name, typ)
 	slice, err := NewBasicSlice(t.state, name, length, cap, typ)
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindSlice, nil, typ, false)
 
 	return slice
 }
 
+// GetSlice gets the slice called name, length and cap are ignored. It
+// panics if name was created as anything but a slice of typ.
 func (t *TypeFactory) GetSlice(name string, length, cap int, typ reflect.Type) Slice {
 	slice, err := GetBasicSlice(t.state, name, typ)
 	if err != nil {
 		panic(err)
 	}
+	t.check(name, KindSlice, nil, typ, false)
 
 	return slice
 }
@@ -133,6 +221,7 @@ func (t *TypeFactory) NewStringSlice(name string, length, cap int, initialData [
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindSlice, nil, StringType, false)
 
 	for i, v := range initialData {
 		slice.Set(i, v)
@@ -141,10 +230,12 @@ func (t *TypeFactory) NewStringSlice(name string, length, cap int, initialData [
 }
 
 func (t *TypeFactory) NewMap(name string, keyType, valType reflect.Type) Map {
+	t.checkFloats(name, keyType, valType)
 	m, err := NewBasicMap(t.state, name, keyType, valType)
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindMap, keyType, valType, false)
 
 	return m
 }
@@ -154,15 +245,18 @@ func (t *TypeFactory) GetMap(name string, keyType, valType reflect.Type) Map {
 	if err != nil {
 		panic(err)
 	}
+	t.check(name, KindMap, keyType, valType, false)
 
 	return m
 }
 
 func (t *TypeFactory) NewIterableMap(name string, keyType, valType reflect.Type) IterableMap {
+	t.checkFloats(name, keyType, valType)
 	m, err := NewBasicIterableMap(t.state, name, keyType, valType)
 	if err != nil {
 		panic(err)
 	}
+	t.describe(name, KindIterableMap, keyType, valType, false)
 
 	return m
 }
@@ -172,6 +266,158 @@ func (t *TypeFactory) GetIterableMap(name string, keyType, valType reflect.Type)
 	if err != nil {
 		panic(err)
 	}
+	t.check(name, KindIterableMap, keyType, valType, false)
+
+	return m
+}
+
+// NewCommittedSlice creates a slice like NewSlice,
+// which also keeps a Merkle root of its elements.
+func (t *TypeFactory) NewCommittedSlice(name string, length, cap int, typ reflect.Type) CommittedSlice {
+	t.checkFloats(name, typ)
+	slice, err := NewBasicSlice(t.state, name, length, cap, typ)
+	if err != nil {
+		panic(err)
+	}
+	t.describe(name, KindSlice, nil, typ, true)
+	slice.arr.commit()
+
+	return slice
+}
+
+// GetCommittedSlice gets a slice created by NewCommittedSlice.
+func (t *TypeFactory) GetCommittedSlice(name string, length, cap int, typ reflect.Type) CommittedSlice {
+	slice, err := GetBasicSlice(t.state, name, typ)
+	if err != nil {
+		panic(err)
+	}
+	t.check(name, KindSlice, nil, typ, true)
+	slice.arr.commit()
+
+	return slice
+}
+
+// NewCommittedIterableMap creates a map like NewIterableMap,
+// which also keeps a Merkle root of its key-value pairs.
+func (t *TypeFactory) NewCommittedIterableMap(name string, keyType, valType reflect.Type) CommittedIterableMap {
+	t.checkFloats(name, keyType, valType)
+	m, err := NewBasicIterableMap(t.state, name, keyType, valType)
+	if err != nil {
+		panic(err)
+	}
+	t.describe(name, KindIterableMap, keyType, valType, true)
+	m.commit()
+
+	return m
+}
+
+// GetCommittedIterableMap gets a map created by NewCommittedIterableMap.
+func (t *TypeFactory) GetCommittedIterableMap(name string, keyType, valType reflect.Type) CommittedIterableMap {
+	m, err := GetBasicIterableMap(t.state, name, keyType, valType)
+	if err != nil {
+		panic(err)
+	}
+	t.check(name, KindIterableMap, keyType, valType, true)
+	m.commit()
 
 	return m
 }
+
+// NewBitmap creates the bitmap called name with every flag cleared.
+func (t *TypeFactory) NewBitmap(name string) Bitmap {
+	b, err := NewBasicBitmap(t.state, name)
+	if err != nil {
+		panic(err)
+	}
+	t.describe(name, KindBitmap, nil, BoolType, false)
+
+	return b
+}
+
+func (t *TypeFactory) GetBitmap(name string) Bitmap {
+	b, err := GetBasicBitmap(t.state, name)
+	if err != nil {
+		panic(err)
+	}
+	t.check(name, KindBitmap, nil, BoolType, false)
+
+	return b
+}
+
+// AddLog emits a log of the contract with topics and data, like the LOG
+// opcodes. It panics with ErrReadOnly if the state is read-only.
+func (t *TypeFactory) AddLog(topics []common.Hash, data []byte) {
+	if t.state.readOnly {
+		panic(ErrReadOnly)
+	}
+
+	t.state.db.AddLog(&types.Log{
+		Address: t.state.addr,
+		Topics:  topics,
+		Data:    data,
+	})
+}
+
+// NewCounter creates the uint256 counter called name holding initialVal.
+func (t *TypeFactory) NewCounter(name string, initialVal *big.Int) Counter {
+	c, err := NewBasicCounter(t.state, name, false, initialVal)
+	if err != nil {
+		panic(err)
+	}
+	t.describe(name, KindCounter, nil, BigIntType, false)
+
+	return c
+}
+
+func (t *TypeFactory) GetCounter(name string) Counter {
+	c, err := GetBasicCounter(t.state, name, false)
+	if err != nil {
+		panic(err)
+	}
+	t.check(name, KindCounter, nil, BigIntType, false)
+
+	return c
+}
+
+// NewIntCounter creates the int256 counter called name holding initialVal.
+func (t *TypeFactory) NewIntCounter(name string, initialVal *big.Int) Counter {
+	c, err := NewBasicCounter(t.state, name, true, initialVal)
+	if err != nil {
+		panic(err)
+	}
+	t.describe(name, KindIntCounter, nil, BigIntType, false)
+
+	return c
+}
+
+func (t *TypeFactory) GetIntCounter(name string) Counter {
+	c, err := GetBasicCounter(t.state, name, true)
+	if err != nil {
+		panic(err)
+	}
+	t.check(name, KindIntCounter, nil, BigIntType, false)
+
+	return c
+}
+
+// NewMapCounter creates the map of uint256 counters called name.
+func (t *TypeFactory) NewMapCounter(name string, keyType reflect.Type) MapCounter {
+	t.checkFloats(name, keyType)
+	mc, err := NewBasicMapCounter(t.state, name, keyType)
+	if err != nil {
+		panic(err)
+	}
+	t.describe(name, KindMapCounter, keyType, BigIntType, false)
+
+	return mc
+}
+
+func (t *TypeFactory) GetMapCounter(name string, keyType reflect.Type) MapCounter {
+	mc, err := GetBasicMapCounter(t.state, name, keyType)
+	if err != nil {
+		panic(err)
+	}
+	t.check(name, KindMapCounter, keyType, BigIntType, false)
+
+	return mc
+}
@@ -0,0 +1,59 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	s, err := Parse(dir, "Contract")
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(s.Fields))
+	assert.Equal(t, Field{Name: "Supply", StorageName: "totalSupply", Kind: KindVariable, Type: "*big.Int"}, s.Fields[1])
+	assert.Equal(t, Field{Name: "Scores", StorageName: "Scores", Kind: KindArray, Type: "int64", Len: 3}, s.Fields[2])
+
+	src, err := s.Generate()
+	assert.Nil(t, err)
+
+	// the checked in example must be up to date
+	expect, err := ioutil.ReadFile(filepath.Join(dir, "contract_storage.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(expect), string(src))
+}
+
+func TestParseErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = Parse(dir, "Missing")
+	assert.ErrorIs(t, err, ErrTypeNotFound)
+
+	tests := []struct {
+		name  string
+		field string
+		err   string
+	}{
+		{"Slice", "Slice [2]int `ethtypes:\"slice\"`", "Contract.Slice: kind slice does not match type [2]int"},
+		{"Kind", "Kind int `ethtypes:\"list\"`", `Contract.Kind: unknown kind "list"`},
+		{"Key", "Key map[*int]string `ethtypes:\"map\"`", "Contract.Key: map keys cannot be pointers"},
+		{"Option", "Option int `ethtypes:\"variable,size=1\"`", `Contract.Option: invalid tag option "size=1"`},
+		{"Embedded", "Base `ethtypes:\"variable\"`", "Contract: embedded field Base cannot be tagged"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fixture := filepath.Join(dir, test.name)
+			assert.Nil(t, os.Mkdir(fixture, 0755))
+			src := "package bad\n\ntype Contract struct {\n\t" + test.field + "\n}\n"
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(fixture, "bad.go"), []byte(src), 0644))
+
+			_, err := Parse(fixture, "Contract")
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const ethtypesImportPath = "github.com/TheStarBoys/ethtypes"
+
+// field is the template view of a Field.
+type field struct {
+	Field
+	// Var is the name of the wrapper's struct field.
+	Var string
+	// Decl declares a zero value v of the value type,
+	// and Ref is a pointer to it.
+	Decl, Ref string
+	// Elem converts the interface{} e yielded by Range to the value type.
+	Elem string
+}
+
+func (f field) Interface() string {
+	switch f.Kind {
+	case KindVariable:
+		return "StateVariable"
+	case KindArray:
+		return "Array"
+	case KindSlice:
+		return "Slice"
+	case KindMap:
+		return "Map"
+	default:
+		return "IterableMap"
+	}
+}
+
+func newField(f Field) field {
+	v := field{Field: f, Var: unexported(f.Name)}
+	if elem := strings.TrimPrefix(f.Type, "*"); elem != f.Type {
+		v.Decl, v.Ref = "v := new("+elem+")", "v"
+		v.Elem = "func() " + f.Type + " { e := e.(" + elem + "); return &e }()"
+	} else {
+		v.Decl, v.Ref = "var v "+f.Type, "&v"
+		v.Elem = "e.(" + f.Type + ")"
+	}
+
+	return v
+}
+
+func unexported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	if s := string(r); !token.Lookup(s).IsKeyword() {
+		return s
+	}
+
+	return string(r) + "_"
+}
+
+type importSpec struct {
+	Name, Path string
+}
+
+// Generate returns the formatted source of the storage wrapper.
+func (s *Storage) Generate() ([]byte, error) {
+	data := struct {
+		*Storage
+		Fields []field
+		// Std are the standard library imports besides reflect,
+		// grouped apart from the rest as goimports would.
+		Std, Imports []importSpec
+	}{Storage: s}
+
+	for _, f := range s.Fields {
+		data.Fields = append(data.Fields, newField(f))
+	}
+	for name, path := range s.Imports {
+		spec := importSpec{Path: path}
+		if path[strings.LastIndex(path, "/")+1:] != name {
+			spec.Name = name
+		}
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			data.Imports = append(data.Imports, spec)
+		} else if path != "reflect" {
+			data.Std = append(data.Std, spec)
+		}
+	}
+	if _, ok := s.Imports["ethtypes"]; !ok {
+		data.Imports = append(data.Imports, importSpec{Path: ethtypesImportPath})
+	}
+
+	var buf bytes.Buffer
+	if err := storageTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v", err)
+	}
+
+	return src, nil
+}
+
+var storageTmpl = template.Must(template.New("storage").Parse(`// Code generated by ethtypesgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"reflect"
+{{- range .Std}}
+	{{.Name}} "{{.Path}}"{{end}}
+{{range .Imports}}
+	{{.Name}} "{{.Path}}"{{end}}
+)
+
+// {{.Name}}Storage provides typed access to the state declared by {{.Name}}.
+type {{.Name}}Storage struct {
+{{- range .Fields}}
+	{{.Var}} ethtypes.{{.Interface}}{{end}}
+}
+
+// Create{{.Name}}Storage declares the state of {{.Name}} in tf and
+// describes it, replacing any state stored under the same names. Call it
+// once, when the contract is created, so that TypeFactory.Open and the
+// kind checks of TypeFactory know the state.
+func Create{{.Name}}Storage(tf *ethtypes.TypeFactory) *{{.Name}}Storage {
+	s := &{{.Name}}Storage{}
+{{- range .Fields}}
+{{- if eq .Kind "variable"}}
+	{
+		{{.Decl}}
+		s.{{.Var}} = tf.NewVariable("{{.StorageName}}", {{.Ref}})
+	}
+{{- else if eq .Kind "array"}}
+	s.{{.Var}} = tf.NewArray("{{.StorageName}}", {{.Len}}, reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- else if eq .Kind "slice"}}
+	s.{{.Var}} = tf.NewSlice("{{.StorageName}}", 0, 0, reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- else if eq .Kind "map"}}
+	s.{{.Var}} = tf.NewMap("{{.StorageName}}", reflect.TypeOf((*{{.KeyType}})(nil)).Elem(), reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- else}}
+	s.{{.Var}} = tf.NewIterableMap("{{.StorageName}}", reflect.TypeOf((*{{.KeyType}})(nil)).Elem(), reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- end}}
+{{- end}}
+
+	return s
+}
+
+// New{{.Name}}Storage opens the state of {{.Name}} in tf, reusing any
+// state already stored under the same names. It does not describe the
+// state, see Create{{.Name}}Storage.
+func New{{.Name}}Storage(tf *ethtypes.TypeFactory) *{{.Name}}Storage {
+	s := &{{.Name}}Storage{}
+{{- range .Fields}}
+{{- if eq .Kind "variable"}}
+	s.{{.Var}} = tf.GetVariable("{{.StorageName}}", reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- else if eq .Kind "array"}}
+	s.{{.Var}} = tf.GetArray("{{.StorageName}}", {{.Len}}, reflect.TypeOf((*{{.Type}})(nil)).Elem())
+	if s.{{.Var}}.Len() != {{.Len}} {
+		s.{{.Var}} = tf.NewArray("{{.StorageName}}", {{.Len}}, reflect.TypeOf((*{{.Type}})(nil)).Elem())
+	}
+{{- else if eq .Kind "slice"}}
+	s.{{.Var}} = tf.GetSlice("{{.StorageName}}", 0, 0, reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- else if eq .Kind "map"}}
+	s.{{.Var}} = tf.GetMap("{{.StorageName}}", reflect.TypeOf((*{{.KeyType}})(nil)).Elem(), reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- else}}
+	s.{{.Var}} = tf.GetIterableMap("{{.StorageName}}", reflect.TypeOf((*{{.KeyType}})(nil)).Elem(), reflect.TypeOf((*{{.Type}})(nil)).Elem())
+{{- end}}
+{{- end}}
+
+	return s
+}
+{{range .Fields}}
+{{- if eq .Kind "variable"}}
+// {{.Name}} returns the value of {{.Name}}.
+func (s *{{$.Name}}Storage) {{.Name}}() {{.Type}} {
+	{{.Decl}}
+	s.{{.Var}}.Get({{.Ref}})
+	return v
+}
+
+// Set{{.Name}} sets {{.Name}} to v.
+func (s *{{$.Name}}Storage) Set{{.Name}}(v {{.Type}}) {
+	s.{{.Var}}.Set(v)
+}
+{{else if or (eq .Kind "array") (eq .Kind "slice")}}
+// {{.Name}} returns the element of {{.Name}} at index i.
+func (s *{{$.Name}}Storage) {{.Name}}(i int) {{.Type}} {
+	{{.Decl}}
+	s.{{.Var}}.Get(i, {{.Ref}})
+	return v
+}
+
+// Set{{.Name}} sets the element of {{.Name}} at index i to v.
+func (s *{{$.Name}}Storage) Set{{.Name}}(i int, v {{.Type}}) {
+	s.{{.Var}}.Set(i, v)
+}
+
+// {{.Name}}Len returns the length of {{.Name}}.
+func (s *{{$.Name}}Storage) {{.Name}}Len() int {
+	return s.{{.Var}}.Len()
+}
+{{if eq .Kind "slice"}}
+// Append{{.Name}} appends vs to {{.Name}}.
+func (s *{{$.Name}}Storage) Append{{.Name}}(vs ...{{.Type}}) {
+	vals := make([]interface{}, len(vs))
+	for i, v := range vs {
+		vals[i] = v
+	}
+	s.{{.Var}}.Append(vals...)
+}
+
+// Pop{{.Name}} removes and returns the last element of {{.Name}}.
+func (s *{{$.Name}}Storage) Pop{{.Name}}() {{.Type}} {
+	{{.Decl}}
+	s.{{.Var}}.Pop({{.Ref}})
+	return v
+}
+{{end}}
+{{- else}}
+// {{.Name}} returns the value of {{.Name}} at key k,
+// and whether it exists.
+func (s *{{$.Name}}Storage) {{.Name}}(k {{.KeyType}}) ({{.Type}}, bool) {
+	{{.Decl}}
+	ok := s.{{.Var}}.Get(k, {{.Ref}})
+	return v, ok
+}
+
+// Set{{.Name}} sets the value of {{.Name}} at key k to v.
+func (s *{{$.Name}}Storage) Set{{.Name}}(k {{.KeyType}}, v {{.Type}}) {
+	s.{{.Var}}.Set(k, v)
+}
+
+// Del{{.Name}} deletes key k from {{.Name}}.
+func (s *{{$.Name}}Storage) Del{{.Name}}(k {{.KeyType}}) {
+	s.{{.Var}}.Del(k)
+}
+{{if eq .Kind "iterablemap"}}
+// {{.Name}}Len returns the number of keys in {{.Name}}.
+func (s *{{$.Name}}Storage) {{.Name}}Len() int {
+	return s.{{.Var}}.Len()
+}
+
+// Range{{.Name}} calls fn for every key-value pair of {{.Name}}
+// in insertion order, until fn returns false.
+func (s *{{$.Name}}Storage) Range{{.Name}}(fn func(k {{.KeyType}}, v {{.Type}}) bool) {
+	s.{{.Var}}.Range(func(k, e interface{}) bool {
+		return fn(k.({{.KeyType}}), {{.Elem}})
+	})
+}
+{{end}}
+{{- end}}
+{{- end}}
+`))
@@ -0,0 +1,20 @@
+// Package example shows the storage wrapper generated by ethtypesgen.
+package example
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:generate go run github.com/TheStarBoys/ethtypes/cmd/ethtypesgen -type Contract
+
+// Contract declares the state of an example contract.
+type Contract struct {
+	Owner    common.Address              `ethtypes:"variable"`
+	Supply   *big.Int                    `ethtypes:"variable,name=totalSupply"`
+	Scores   [3]int64                    `ethtypes:"array"`
+	Members  []string                    `ethtypes:"slice"`
+	Balances map[common.Address]*big.Int `ethtypes:"map"`
+	Votes    map[string]uint32           `ethtypes:"iterablemap"`
+}
@@ -0,0 +1,69 @@
+package example
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractStorage(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := ethtypes.NewTypeFactory(statedb, common.HexToAddress("123"))
+
+	s := CreateContractStorage(tf)
+	owner := common.HexToAddress("456")
+	s.SetOwner(owner)
+	s.SetSupply(big.NewInt(100))
+	s.SetScores(1, 7)
+	s.AppendMembers("alice", "bob")
+	s.SetBalances(owner, big.NewInt(100))
+	s.SetVotes("yes", 2)
+	s.SetVotes("no", 1)
+
+	// reopening the storage must not reset anything
+	s = NewContractStorage(tf)
+	assert.Equal(t, owner, s.Owner())
+	assert.Equal(t, big.NewInt(100), s.Supply())
+	assert.Equal(t, 3, s.ScoresLen())
+	assert.Equal(t, int64(7), s.Scores(1))
+	assert.Equal(t, 2, s.MembersLen())
+	assert.Equal(t, "bob", s.PopMembers())
+	assert.Equal(t, 1, s.MembersLen())
+
+	balance, ok := s.Balances(owner)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(100), balance)
+	s.DelBalances(owner)
+	_, ok = s.Balances(owner)
+	assert.False(t, ok)
+
+	votes := make(map[string]uint32)
+	s.RangeVotes(func(k string, v uint32) bool {
+		votes[k] = v
+		return true
+	})
+	assert.Equal(t, map[string]uint32{"yes": 2, "no": 1}, votes)
+
+	// the state is described, so it opens by name
+	for name, kind := range map[string]string{
+		"Owner":       ethtypes.KindVariable,
+		"totalSupply": ethtypes.KindVariable,
+		"Scores":      ethtypes.KindArray,
+		"Members":     ethtypes.KindSlice,
+		"Balances":    ethtypes.KindMap,
+		"Votes":       ethtypes.KindIterableMap,
+	} {
+		got, _, err := tf.Kind(name)
+		assert.NoError(t, err)
+		assert.Equal(t, kind, got, name)
+		_, err = tf.Open(name)
+		assert.NoError(t, err, name)
+	}
+	assert.Panics(t, func() { tf.GetMap("Votes", ethtypes.StringType, ethtypes.Uint32Type) })
+}
@@ -0,0 +1,223 @@
+// Package gen generates typed wrappers around ethtypes.TypeFactory
+// from Go struct definitions.
+//
+// Every field of the struct that carries an `ethtypes` tag is declared
+// as a state variable or container. The first tag element is the kind,
+// which must agree with the Go type of the field:
+//
+//	Owner    common.Address            `ethtypes:"variable"`
+//	Scores   [3]int64                  `ethtypes:"array"`
+//	Members  []string                  `ethtypes:"slice"`
+//	Balances map[common.Address]uint64 `ethtypes:"map"`
+//	Votes    map[string]uint32         `ethtypes:"iterablemap,name=votes"`
+//
+// The optional name element overrides the storage name, which defaults
+// to the field name.
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	KindVariable    = "variable"
+	KindArray       = "array"
+	KindSlice       = "slice"
+	KindMap         = "map"
+	KindIterableMap = "iterablemap"
+
+	tagKey = "ethtypes"
+)
+
+var ErrTypeNotFound = errors.New("type not found")
+
+// Field is a state variable or container declared by a struct field.
+type Field struct {
+	// Name is the Go name of the field.
+	Name string
+	// StorageName is the name the field is stored under.
+	StorageName string
+	Kind        string
+	// Type is the variable type, or the element/value type of a container,
+	// as written in the source.
+	Type string
+	// KeyType is the key type of maps.
+	KeyType string
+	// Len is the length of arrays.
+	Len int
+}
+
+// Storage describes a struct whose fields are state variables.
+type Storage struct {
+	Package string
+	Name    string
+	Fields  []Field
+	// Imports are the import paths, keyed by package name,
+	// referenced by field types.
+	Imports map[string]string
+}
+
+// Parse finds the struct typeName among the non-test Go files in dir.
+func Parse(dir, typeName string) (*Storage, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if ts.Name.Name != typeName {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						return nil, fmt.Errorf("%s is not a struct", typeName)
+					}
+					return parseStruct(file, typeName, st)
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrTypeNotFound, typeName)
+}
+
+func parseStruct(file *ast.File, typeName string, st *ast.StructType) (*Storage, error) {
+	s := &Storage{
+		Package: file.Name.Name,
+		Name:    typeName,
+		Imports: make(map[string]string),
+	}
+
+	imports := fileImports(file)
+	for _, f := range st.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+		tagStr, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		tag, ok := reflect.StructTag(tagStr).Lookup(tagKey)
+		if !ok {
+			continue
+		}
+
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded field %s cannot be tagged", typeName, types.ExprString(f.Type))
+		}
+		for _, name := range f.Names {
+			field, err := parseField(name.Name, tag, f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", typeName, name.Name, err)
+			}
+			s.Fields = append(s.Fields, field)
+		}
+
+		for _, pkg := range referencedPackages(f.Type) {
+			path, ok := imports[pkg]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown package %s", typeName, pkg)
+			}
+			s.Imports[pkg] = path
+		}
+	}
+
+	return s, nil
+}
+
+func parseField(name, tag string, typ ast.Expr) (Field, error) {
+	opts := strings.Split(tag, ",")
+	field := Field{
+		Name:        name,
+		StorageName: name,
+		Kind:        opts[0],
+	}
+	for _, opt := range opts[1:] {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 || kv[0] != "name" || kv[1] == "" {
+			return Field{}, fmt.Errorf("invalid tag option %q", opt)
+		}
+		field.StorageName = kv[1]
+	}
+
+	switch field.Kind {
+	case KindVariable:
+		field.Type = types.ExprString(typ)
+	case KindArray, KindSlice:
+		at, ok := typ.(*ast.ArrayType)
+		if !ok || (at.Len == nil) != (field.Kind == KindSlice) {
+			return Field{}, fmt.Errorf("kind %s does not match type %s", field.Kind, types.ExprString(typ))
+		}
+		if field.Kind == KindArray {
+			lit, ok := at.Len.(*ast.BasicLit)
+			if !ok || lit.Kind != token.INT {
+				return Field{}, fmt.Errorf("array length must be an integer literal")
+			}
+			field.Len, _ = strconv.Atoi(lit.Value)
+		}
+		field.Type = types.ExprString(at.Elt)
+	case KindMap, KindIterableMap:
+		mt, ok := typ.(*ast.MapType)
+		if !ok {
+			return Field{}, fmt.Errorf("kind %s does not match type %s", field.Kind, types.ExprString(typ))
+		}
+		if _, ok := mt.Key.(*ast.StarExpr); ok {
+			return Field{}, fmt.Errorf("map keys cannot be pointers")
+		}
+		field.KeyType = types.ExprString(mt.Key)
+		field.Type = types.ExprString(mt.Value)
+	default:
+		return Field{}, fmt.Errorf("unknown kind %q", field.Kind)
+	}
+
+	return field, nil
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+
+	return imports
+}
+
+func referencedPackages(typ ast.Expr) []string {
+	var pkgs []string
+	ast.Inspect(typ, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				pkgs = append(pkgs, id.Name)
+			}
+			return false
+		}
+		return true
+	})
+
+	return pkgs
+}
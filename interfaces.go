@@ -1,6 +1,7 @@
 package ethtypes
 
 import (
+	"math/big"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -40,10 +41,45 @@ type Array interface {
 	// move all elements after index move forward by one position
 	Del(index int)
 	Len() int
-	CopyFrom(src Array, dstFrom, srcFrom, srcTo int)
+	// CopyFrom copies the elements of src in [srcFrom, srcTo)
+	// into the array from index dstFrom on. src may be the array
+	// itself, overlapping ranges are copied like memmove.
+	// It returns ErrIndexOutOfRange if either range is out of bounds,
+	// and ErrKindMismatch if the element kinds are different.
+	CopyFrom(src Array, dstFrom, srcFrom, srcTo int) error
+	// GetRange get elements in [from, to) into vals,
+	// vals must be pointer to a slice
+	GetRange(from, to int, vals interface{})
+	// SetRange set elements from index from on as
+	// the elements of the slice vals
+	SetRange(from int, vals interface{})
+	// Swap swaps the elements in i and j
+	Swap(i, j int)
+	// Reverse reverses the order of elements
+	Reverse()
+	// Fill set every element as val
+	Fill(val interface{})
+	// Sort sorts the elements by less, a nil less sorts
+	// by NaturalLess. Only the elements that moved are written
+	Sort(less Less)
+	// IsSorted reports whether the elements are sorted by less
+	IsSorted(less Less) bool
+	// SearchSorted binary searches val in the sorted elements,
+	// and returns the index of the first element not less than val
+	SearchSorted(val interface{}, less Less) int
 	// ElemType returns element type of the array
 	ElemType() reflect.Type
-	// Range(fn func(index int, val interface{}) bool)
+	// Range iterates all elements in order, it
+	// will stop if fn returns false
+	Range(fn func(index int, val interface{}) bool)
+	// Iter returns an iterator over all elements
+	Iter() Iterator
+	// ReverseIter returns an iterator over all
+	// elements from the last one
+	ReverseIter() Iterator
+	// Page returns an iterator over at most limit
+	// elements from index offset on
+	Page(offset, limit int) Iterator
 	// Name returns the name of array
 	Name() string
 }
@@ -55,8 +91,25 @@ type Slice interface {
 	Cap() int
 	// Append append elements in the tail of the slice
 	Append(vals ...interface{})
-	// Pop remove elements in the tail of the slice
+	// Insert inserts elements before index, and move all
+	// elements from index on backward
+	Insert(index int, vals ...interface{})
+	// InsertSorted inserts val into the sorted slice, keeping it
+	// sorted by less, and returns its index
+	InsertSorted(val interface{}, less Less) int
+	// RemoveSorted removes an element equal to val from
+	// the sorted slice, and reports whether there was one
+	RemoveSorted(val interface{}, less Less) bool
+	// Pop remove elements in the tail of the slice,
+	// and deletes its storage
 	Pop(val interface{})
+	// Truncate removes the elements from index n on,
+	// deleting their storage
+	Truncate(n int)
+	// Clear removes all elements
+	Clear()
+	// ShrinkToFit reduces the capacity to the length
+	ShrinkToFit()
 }
 
 // Map represents key-value pair mapping
@@ -84,4 +137,125 @@ type IterableMap interface {
 	// Range iterate all key-value pair, it
 	// will stop if fn returns false
 	Range(fn func(key, val interface{}) bool)
+	// Iter returns an iterator over all key-value
+	// pairs in insertion order
+	Iter() Iterator
+	// ReverseIter returns an iterator over all key-value
+	// pairs from the last inserted one
+	ReverseIter() Iterator
+	// Page returns an iterator over at most limit
+	// key-value pairs from index offset on
+	Page(offset, limit int) Iterator
+}
+
+// Iterator iterates the elements of a container:
+//
+//	it := arr.Iter()
+//	for it.Next() {
+//		fmt.Println(it.Key(), it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Breaking out of the loop ends the iteration early.
+type Iterator interface {
+	// Next moves to the next element, it returns false
+	// when there are no more elements or an error occurred
+	Next() bool
+	// Key returns the index of array elements,
+	// or the key of map elements
+	Key() interface{}
+	// Value returns the current element
+	Value() interface{}
+	// Err returns the error that stopped the iteration,
+	// ErrConcurrentModification if the length of the container
+	// changed, or ErrIndexOutOfRange for an invalid page
+	Err() error
+}
+
+// CommittedSlice is a Slice which keeps a Merkle root of its elements
+// up to date, so that light clients can verify them with VerifyProof.
+// Unassigned elements are empty leaves.
+//
+// The tree has a depth of 32: writing an element also reads the 32
+// siblings of its leaf and writes 33 nodes, about 32 * 800 + 33 * 5000
+// gas after EIP-2200 on top of the element itself. Only the handles
+// returned by NewCommitted*, GetCommitted* and Open update the tree,
+// so TypeFactory refuses to get a committed name as a plain container.
+type CommittedSlice interface {
+	Slice
+	// Root returns the Merkle root of the elements
+	Root() common.Hash
+	// Proof returns the Merkle proof of the element in index
+	Proof(index int) (*MerkleProof, error)
+}
+
+// CommittedIterableMap is an IterableMap which keeps a Merkle root
+// of its key-value pairs, with the leaves in insertion order. Writes
+// cost like those of a CommittedSlice, and deleting a pair rewrites
+// the leaves of every pair inserted after it.
+type CommittedIterableMap interface {
+	IterableMap
+	// Root returns the Merkle root of the key-value pairs
+	Root() common.Hash
+	// Proof returns the Merkle proof of the pair of key,
+	// or ErrKeyNotFound if there is none
+	Proof(key interface{}) (*MerkleProof, error)
+}
+
+// Bitmap is a set of indexes from 0 to MaxBitmapIndex,
+// 256 flags share one storage slot.
+type Bitmap interface {
+	// Name returns the name of the bitmap
+	Name() string
+	// Set sets the flag of index i
+	Set(i int)
+	// Unset clears the flag of index i
+	Unset(i int)
+	// Test reports whether the flag of index i is set
+	Test(i int) bool
+	// Count returns the number of set flags
+	Count() int
+	// NextSet returns the first set index from i on,
+	// ok is false if there is none. It reads a bounded
+	// number of slots however far the next index is
+	NextSet(i int) (next int, ok bool)
+	// SetRange sets the flags of [from, to),
+	// writing each slot at most once
+	SetRange(from, to int)
+	// UnsetRange clears the flags of [from, to)
+	UnsetRange(from, to int)
+	// Clear clears every flag, writing each non-zero slot once
+	Clear()
+}
+
+// Counter is a uint256 or int256 variable updated in place,
+// every update returns the new value. Updates out of range
+// panic with ErrOverflow or ErrUnderflow.
+type Counter interface {
+	// Name returns the name of the counter
+	Name() string
+	// Signed reports whether the counter is an int256
+	Signed() bool
+	Get() *big.Int
+	Set(val *big.Int)
+	Inc() *big.Int
+	Dec() *big.Int
+	Add(delta *big.Int) *big.Int
+	Sub(delta *big.Int) *big.Int
+}
+
+// MapCounter maps keys to uint256 counters, like a Map
+// whose values are updated in place.
+type MapCounter interface {
+	// Name returns the name of the map
+	Name() string
+	// KeyType returns the type of the keys
+	KeyType() reflect.Type
+	// Get returns the counter of key, zero if it is not set
+	Get(key interface{}) *big.Int
+	Set(key interface{}, val *big.Int)
+	Add(key interface{}, delta *big.Int) *big.Int
+	Sub(key interface{}, delta *big.Int) *big.Int
 }
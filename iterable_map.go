@@ -3,17 +3,24 @@ package ethtypes
 import (
 	"fmt"
 	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type BasicIterableMap struct {
-	data Map
+	data *BasicMap
 	// keys[index] = key
 	keys Slice
+	// tree commits to the key-value pairs by their index in keys,
+	// and index[key] = index, both are nil if it is not committed
+	tree  *merkleTree
+	index *BasicMap
 }
 
 const (
 	iterableMapKeysInitialSize = 10
 	iterableMapKeysPrefix      = "iterable_map_keys_"
+	iterableMapIndexPrefix     = "iterable_map_index_"
 )
 
 func NewBasicIterableMap(state *ContractState, name string, keyType, valType reflect.Type) (*BasicIterableMap, error) {
@@ -57,26 +64,74 @@ func (im *BasicIterableMap) Name() string {
 }
 
 func (im *BasicIterableMap) Get(key interface{}, val interface{}) (ok bool) {
-	return im.data.Get(key, val)
+	return im.data.Get(im.key(key), val)
+}
+
+// key returns key converted to the key type in strict mode,
+// so it compares equal to the stored keys.
+func (im *BasicIterableMap) key(key interface{}) interface{} {
+	if !im.data.state.strict {
+		return key
+	}
+
+	v := reflect.ValueOf(key)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return checkValue("key of", im.data.name, im.data.keyType, v).Interface()
+}
+
+// commit keeps the Merkle root of the key-value pairs up to date,
+// it must be used from the creation of the map on.
+func (im *BasicIterableMap) commit() {
+	name := im.data.Name()
+	im.tree = newMerkleTree(im.data.state, iterableMapKeysPrefix+name)
+	im.index, _ = GetBasicMap(im.data.state, iterableMapIndexPrefix+name, im.data.keyType, IntType)
+}
+
+// position returns the index of key in keys.
+func (im *BasicIterableMap) position(key interface{}) int {
+	var i int
+	im.index.Get(key, &i)
+	return i
+}
+
+// update sets the leaf in index i as the pair of key and its value.
+func (im *BasicIterableMap) update(i int, key interface{}) {
+	im.tree.update(i, mapLeaf(encodeKey(key), im.data.getElem(key).raw()))
 }
 
 func (im *BasicIterableMap) Set(key, val interface{}) {
+	key = im.key(key)
 	if !im.data.Contains(key) {
 		im.keys.Append(key)
+		if im.tree != nil {
+			im.index.Set(key, im.keys.Len()-1)
+		}
 	}
 	im.data.Set(key, val)
+
+	if im.tree != nil {
+		im.update(im.position(key), key)
+	}
 }
 
 func (im *BasicIterableMap) Contains(key interface{}) bool {
-	return im.data.Contains(key)
+	return im.data.Contains(im.key(key))
 }
 
 func (im *BasicIterableMap) Del(key interface{}) {
+	key = im.key(key)
 	if !im.Contains(key) {
 		return
 	}
 
 	im.data.Del(key)
+	if im.tree != nil {
+		im.delCommitted(key)
+		return
+	}
+
 	for i := 0; i < im.keys.Len(); i++ {
 		keyType := im.keys.ElemType()
 		k := reflect.New(keyType).Interface()
@@ -90,6 +145,51 @@ func (im *BasicIterableMap) Del(key interface{}) {
 	panic(fmt.Sprintf("cannot find key %v in keys", key))
 }
 
+// delCommitted removes key from keys, and updates the
+// leaves and the indexes of the keys after it.
+func (im *BasicIterableMap) delCommitted(key interface{}) {
+	pos := im.position(key)
+	im.keys.Del(pos)
+	im.index.Del(key)
+
+	length := im.keys.Len()
+	for i := pos; i < length; i++ {
+		k := reflect.New(im.keys.ElemType())
+		im.keys.Get(i, k.Interface())
+		im.index.Set(k.Elem().Interface(), i)
+		im.update(i, k.Elem().Interface())
+	}
+	im.tree.update(length, common.Hash{})
+}
+
+// Root returns the Merkle root of the key-value pairs,
+// or the zero hash if the map is not committed.
+func (im *BasicIterableMap) Root() common.Hash {
+	if im.tree == nil {
+		return common.Hash{}
+	}
+
+	return im.tree.root()
+}
+
+func (im *BasicIterableMap) Proof(key interface{}) (*MerkleProof, error) {
+	if im.tree == nil {
+		return nil, ErrNotCommitted
+	}
+	key = im.key(key)
+	if !im.Contains(key) {
+		return nil, fmt.Errorf("%w: %v", ErrKeyNotFound, key)
+	}
+
+	pos := im.position(key)
+	return &MerkleProof{
+		Index:    uint64(pos),
+		Key:      encodeKey(key),
+		Value:    im.data.getElem(key).raw(),
+		Siblings: im.tree.proof(pos),
+	}, nil
+}
+
 func (im *BasicIterableMap) Len() int {
 	return im.keys.Len()
 }
@@ -120,3 +220,15 @@ func (im *BasicIterableMap) Range(fn func(key, val interface{}) bool) {
 		}
 	}
 }
+
+func (im *BasicIterableMap) Iter() Iterator {
+	return IterMap(im, 0, -1, false)
+}
+
+func (im *BasicIterableMap) ReverseIter() Iterator {
+	return IterMap(im, 0, -1, true)
+}
+
+func (im *BasicIterableMap) Page(offset, limit int) Iterator {
+	return IterMap(im, offset, limit, false)
+}
@@ -0,0 +1,105 @@
+package ethtypes
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type indexIterator struct {
+	length func() int
+	load   func(i int) (key, val interface{})
+
+	// n is the length when the iterator was created
+	n    int
+	next int
+	// left is the number of elements left, negative for no limit
+	left int
+	step int
+
+	key, val interface{}
+	err      error
+}
+
+func newIndexIterator(length func() int, load func(i int) (key, val interface{}), offset, limit int, reverse bool) *indexIterator {
+	it := &indexIterator{
+		length: length,
+		load:   load,
+		n:      length(),
+		left:   limit,
+		step:   1,
+	}
+	if offset < 0 || limit < -1 {
+		it.err = fmt.Errorf("%w: page offset %d, limit %d", ErrIndexOutOfRange, offset, limit)
+	}
+
+	it.next = offset
+	if reverse {
+		it.next = it.n - 1 - offset
+		it.step = -1
+	}
+
+	return it
+}
+
+func (it *indexIterator) Next() bool {
+	if it.err != nil || it.left == 0 || it.next < 0 || it.next >= it.n {
+		return false
+	}
+	if it.length() != it.n {
+		it.err = ErrConcurrentModification
+		return false
+	}
+
+	it.key, it.val = it.load(it.next)
+	it.next += it.step
+	if it.left > 0 {
+		it.left--
+	}
+
+	return true
+}
+
+func (it *indexIterator) Key() interface{} {
+	return it.key
+}
+
+func (it *indexIterator) Value() interface{} {
+	return it.val
+}
+
+func (it *indexIterator) Err() error {
+	return it.err
+}
+
+// IterArray returns an iterator over at most limit elements of arr
+// from offset on, a limit of -1 means no limit. A reverse iterator
+// counts offset from the last element. Key returns the index.
+func IterArray(arr Array, offset, limit int, reverse bool) Iterator {
+	return newIndexIterator(arr.Len, func(i int) (key, val interface{}) {
+		v := reflect.New(arr.ElemType())
+		arr.Get(i, v.Interface())
+		return i, v.Elem().Interface()
+	}, offset, limit, reverse)
+}
+
+// IterMap returns an iterator over the key-value pairs of m
+// in insertion order like IterArray.
+func IterMap(m IterableMap, offset, limit int, reverse bool) Iterator {
+	return newIndexIterator(m.Len, func(i int) (key, val interface{}) {
+		keyType, valType := m.GetKVType()
+		k, v := reflect.New(keyType), reflect.New(valType)
+		m.Index(i, k.Interface(), v.Interface())
+		return k.Elem().Interface(), v.Elem().Interface()
+	}, offset, limit, reverse)
+}
+
+// RangeArray calls fn for each element of arr in order,
+// it stops if fn returns false.
+func RangeArray(arr Array, fn func(index int, val interface{}) bool) {
+	it := IterArray(arr, 0, -1, false)
+	for it.Next() {
+		if !fn(it.Key().(int), it.Value()) {
+			return
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package layout
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Array is a fixed-size or dynamic array. Elements of up to 16 bytes are
+// packed into shared slots like Solidity does.
+type Array struct {
+	c    *Contract
+	name string
+	// slot holds the length of dynamic arrays
+	slot common.Hash
+	// data is the slot of the first element
+	data common.Hash
+	base *Type
+	typ  reflect.Type
+	// fixedLen is the length of fixed-size arrays
+	fixedLen int
+}
+
+var _ ethtypes.Array = (*Array)(nil)
+
+func (a *Array) Name() string {
+	return a.name
+}
+
+func (a *Array) ElemType() reflect.Type {
+	return a.typ
+}
+
+func (a *Array) dynamic() bool {
+	return a.data != a.slot
+}
+
+func (a *Array) Len() int {
+	if !a.dynamic() {
+		return a.fixedLen
+	}
+
+	return int(a.c.db.GetState(a.c.addr, a.slot).Big().Int64())
+}
+
+func (a *Array) setLen(n int) {
+	a.c.db.SetState(a.c.addr, a.slot, common.BigToHash(big.NewInt(int64(n))))
+}
+
+// perSlot returns the number of elements packed into one slot.
+func (a *Array) perSlot() int {
+	if size := a.base.size(); size <= 16 {
+		return 32 / size
+	}
+
+	return 1
+}
+
+// stride returns the number of slots of one element.
+func (a *Array) stride() int {
+	return (a.base.size() + 31) / 32
+}
+
+func (a *Array) elem(index int) *cell {
+	cl := &cell{c: a.c, t: a.base, typ: a.typ}
+	if perSlot := a.perSlot(); perSlot > 1 {
+		cl.slot = addSlot(a.data, index/perSlot)
+		cl.offset = index % perSlot * a.base.size()
+	} else {
+		cl.slot = addSlot(a.data, index*a.stride())
+	}
+
+	return cl
+}
+
+func (a *Array) checkIndex(index int) {
+	if index < 0 || index >= a.Len() {
+		panic(ethtypes.ErrIndexOutOfRange)
+	}
+}
+
+func (a *Array) Get(index int, val interface{}) {
+	a.checkIndex(index)
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr {
+		panic("val must be pointer")
+	}
+	if rv.Elem().Type() != a.typ {
+		panic(fmt.Sprintf("expect type: %v, actual type: %v", a.typ, rv.Elem().Type()))
+	}
+
+	a.elem(index).get(rv.Elem())
+}
+
+func (a *Array) Set(index int, val interface{}) {
+	a.checkIndex(index)
+	a.elem(index).set(reflect.ValueOf(val))
+}
+
+// Del deletes the element in index and moves all elements
+// after it forward by one position.
+func (a *Array) Del(index int) {
+	a.checkIndex(index)
+	length := a.Len()
+	a.move(a, index, index+1, length)
+	a.elem(length - 1).clear()
+}
+
+func (a *Array) CopyFrom(src ethtypes.Array, dstFrom, srcFrom, srcTo int) error {
+	if srcFrom < 0 || srcFrom > srcTo || srcTo > src.Len() {
+		return fmt.Errorf("%w: source range [%d, %d) of length %d", ethtypes.ErrIndexOutOfRange, srcFrom, srcTo, src.Len())
+	}
+	if dstFrom < 0 || srcTo-srcFrom > a.Len()-dstFrom {
+		return fmt.Errorf("%w: copy %d elements to %d of length %d", ethtypes.ErrIndexOutOfRange, srcTo-srcFrom, dstFrom, a.Len())
+	}
+	if src.ElemType().Kind() != a.typ.Kind() {
+		return fmt.Errorf("%w: expect kind: %v, actual kind: %v", ethtypes.ErrKindMismatch, a.typ.Kind(), src.ElemType().Kind())
+	}
+
+	a.move(src, dstFrom, srcFrom, srcTo)
+	return nil
+}
+
+// move copies backward when the destination is after the source,
+// so overlapping ranges of the same array are handled like memmove.
+func (a *Array) move(src ethtypes.Array, dstFrom, srcFrom, srcTo int) {
+	copyElem := func(i int) {
+		val := reflect.New(src.ElemType())
+		src.Get(i, val.Interface())
+		a.Set(dstFrom+i-srcFrom, val.Elem().Interface())
+	}
+
+	if dstFrom > srcFrom {
+		for i := srcTo - 1; i >= srcFrom; i-- {
+			copyElem(i)
+		}
+		return
+	}
+	for i := srcFrom; i < srcTo; i++ {
+		copyElem(i)
+	}
+}
+
+func (a *Array) GetRange(from, to int, vals interface{}) {
+	if from < 0 || from > to || to > a.Len() {
+		panic(ethtypes.ErrIndexOutOfRange)
+	}
+
+	rv := reflect.ValueOf(vals)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		panic("vals must be pointer to slice")
+	}
+
+	res := reflect.MakeSlice(rv.Elem().Type(), to-from, to-from)
+	for i := from; i < to; i++ {
+		a.elem(i).get(res.Index(i - from))
+	}
+	rv.Elem().Set(res)
+}
+
+func (a *Array) SetRange(from int, vals interface{}) {
+	rv := reflect.ValueOf(vals)
+	if rv.Kind() != reflect.Slice {
+		panic("vals must be slice")
+	}
+	if from < 0 || from+rv.Len() > a.Len() {
+		panic(ethtypes.ErrIndexOutOfRange)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		a.elem(from + i).set(rv.Index(i))
+	}
+}
+
+// load returns the element in index without checking it.
+func (a *Array) load(index int) reflect.Value {
+	val := reflect.New(a.typ).Elem()
+	a.elem(index).get(val)
+	return val
+}
+
+func (a *Array) Swap(i, j int) {
+	a.checkIndex(i)
+	a.checkIndex(j)
+
+	vi, vj := a.load(i), a.load(j)
+	if !reflect.DeepEqual(vi.Interface(), vj.Interface()) {
+		a.elem(i).set(vj)
+		a.elem(j).set(vi)
+	}
+}
+
+func (a *Array) Reverse() {
+	for i, j := 0, a.Len()-1; i < j; i, j = i+1, j-1 {
+		a.Swap(i, j)
+	}
+}
+
+func (a *Array) Fill(val interface{}) {
+	rv := reflect.ValueOf(val)
+	for i, length := 0, a.Len(); i < length; i++ {
+		if !reflect.DeepEqual(a.load(i).Interface(), val) {
+			a.elem(i).set(rv)
+		}
+	}
+}
+
+// Slice is a dynamic array.
+type Slice struct {
+	*Array
+}
+
+var _ ethtypes.Slice = (*Slice)(nil)
+
+// Cap returns the length, storage is allocated per element.
+func (s *Slice) Cap() int {
+	return s.Len()
+}
+
+// Del deletes the element in index and shortens the array by one.
+func (s *Slice) Del(index int) {
+	s.Array.Del(index)
+	s.setLen(s.Len() - 1)
+}
+
+func (s *Slice) Append(vals ...interface{}) {
+	length := s.Len()
+	s.setLen(length + len(vals))
+	for i, v := range vals {
+		s.Set(length+i, v)
+	}
+}
+
+// Insert moves the elements after index backward by len(vals)
+// and stores vals in their place.
+func (s *Slice) Insert(index int, vals ...interface{}) {
+	length := s.Len()
+	if index < 0 || index > length {
+		panic(ethtypes.ErrIndexOutOfRange)
+	}
+
+	s.setLen(length + len(vals))
+	for i := length - 1; i >= index; i-- {
+		s.elem(i + len(vals)).set(s.load(i))
+	}
+	for i, v := range vals {
+		s.Set(index+i, v)
+	}
+}
+
+// Truncate removes the elements from index n on, clearing their storage.
+func (s *Slice) Truncate(n int) {
+	length := s.Len()
+	if n < 0 || n > length {
+		panic(ethtypes.ErrIndexOutOfRange)
+	}
+
+	for i := n; i < length; i++ {
+		s.elem(i).clear()
+	}
+	s.setLen(n)
+}
+
+func (s *Slice) Clear() {
+	s.Truncate(0)
+}
+
+// ShrinkToFit does nothing, the capacity is always the length.
+func (s *Slice) ShrinkToFit() {}
+
+// Pop removes the last element like Solidity's pop, clearing its storage.
+func (s *Slice) Pop(val interface{}) {
+	length := s.Len()
+	s.Get(length-1, val)
+	s.elem(length - 1).clear()
+	s.setLen(length - 1)
+}
+
+func (a *Array) Sort(less ethtypes.Less) {
+	ethtypes.SortArray(a, less)
+}
+
+func (a *Array) IsSorted(less ethtypes.Less) bool {
+	return ethtypes.IsArraySorted(a, less)
+}
+
+func (a *Array) SearchSorted(val interface{}, less ethtypes.Less) int {
+	return ethtypes.SearchArray(a, val, less)
+}
+
+func (s *Slice) InsertSorted(val interface{}, less ethtypes.Less) int {
+	return ethtypes.InsertSorted(s, val, less)
+}
+
+func (s *Slice) RemoveSorted(val interface{}, less ethtypes.Less) bool {
+	return ethtypes.RemoveSorted(s, val, less)
+}
+
+func (a *Array) Range(fn func(index int, val interface{}) bool) {
+	ethtypes.RangeArray(a, fn)
+}
+
+func (a *Array) Iter() ethtypes.Iterator {
+	return ethtypes.IterArray(a, 0, -1, false)
+}
+
+func (a *Array) ReverseIter() ethtypes.Iterator {
+	return ethtypes.IterArray(a, 0, -1, true)
+}
+
+func (a *Array) Page(offset, limit int) ethtypes.Iterator {
+	return ethtypes.IterArray(a, offset, limit, false)
+}
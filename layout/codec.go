@@ -0,0 +1,132 @@
+package layout
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+)
+
+// encode returns the big-endian storage bytes of an inplace value.
+func encode(t *Type, v reflect.Value) ([]byte, error) {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	size := t.size()
+	switch v.Kind() {
+	case reflect.Bool:
+		b := make([]byte, size)
+		if v.Bool() {
+			b[size-1] = 1
+		}
+		return b, nil
+	case reflect.Array:
+		// address, contract and bytesN values
+		if v.Len() != size || v.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("cannot store %v as %s", v.Type(), t.Label)
+		}
+		b := make([]byte, size)
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b, nil
+	}
+
+	var x *big.Int
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x = big.NewInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x = new(big.Int).SetUint64(v.Uint())
+	case reflect.Struct:
+		if v.Type() != ethtypes.BigIntType {
+			return nil, fmt.Errorf("cannot store %v as %s", v.Type(), t.Label)
+		}
+		i := v.Interface().(big.Int)
+		x = &i
+	default:
+		return nil, fmt.Errorf("cannot store %v as %s", v.Type(), t.Label)
+	}
+
+	bits := uint(size * 8)
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), bits)
+	if t.signed() {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if x.Cmp(min) < 0 || x.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("%v overflows %s", x, t.Label)
+	}
+	if x.Sign() < 0 {
+		x = new(big.Int).Add(x, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+
+	b := make([]byte, size)
+	return x.FillBytes(b), nil
+}
+
+// decode stores the storage bytes b of an inplace value into dst.
+func decode(t *Type, b []byte, dst reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Bool:
+		dst.SetBool(b[len(b)-1] != 0)
+		return
+	case reflect.Array:
+		reflect.Copy(dst, reflect.ValueOf(b))
+		return
+	}
+
+	x := new(big.Int).SetBytes(b)
+	if bits := uint(len(b) * 8); t.signed() && x.Bit(int(bits)-1) == 1 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetInt(x.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		dst.SetUint(x.Uint64())
+	default:
+		dst.Set(reflect.ValueOf(*x))
+	}
+}
+
+// hashKey returns the bytes a mapping key is hashed with: value types are
+// padded to 32 bytes, strings and bytes are used as is.
+func hashKey(t *Type, key reflect.Value) ([]byte, error) {
+	for key.Kind() == reflect.Ptr {
+		key = key.Elem()
+	}
+
+	if t.Encoding == encodingBytes {
+		switch key.Kind() {
+		case reflect.String:
+			return []byte(key.String()), nil
+		case reflect.Slice:
+			return key.Bytes(), nil
+		default:
+			return nil, fmt.Errorf("cannot use %v as %s key", key.Type(), t.Label)
+		}
+	}
+
+	b, err := encode(t, key)
+	if err != nil {
+		return nil, err
+	}
+
+	padded := make([]byte, 32)
+	if key.Kind() == reflect.Array && key.Type() != ethtypes.AddressType {
+		// bytesN keys are left aligned
+		copy(padded, b)
+		return padded, nil
+	}
+	// sign extend negative integers
+	if t.signed() && b[0]&0x80 != 0 {
+		for i := 0; i < 32-len(b); i++ {
+			padded[i] = 0xff
+		}
+	}
+	copy(padded[32-len(b):], b)
+
+	return padded, nil
+}
@@ -0,0 +1,350 @@
+package layout
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Contract binds a storage layout to the account of a contract.
+type Contract struct {
+	db     vm.StateDB
+	addr   common.Address
+	layout *Layout
+}
+
+func NewContract(db vm.StateDB, addr common.Address, layout *Layout) *Contract {
+	return &Contract{
+		db:     db,
+		addr:   addr,
+		layout: layout,
+	}
+}
+
+// Variable returns the value type, string or bytes variable label.
+func (c *Contract) Variable(label string) (ethtypes.StateVariable, error) {
+	s, typ, err := c.lookup(label)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := c.newCell(slotOf(s), s.Offset, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Variable{cell: cl, name: label}, nil
+}
+
+// Array returns the fixed-size array variable label.
+func (c *Contract) Array(label string) (ethtypes.Array, error) {
+	s, typ, err := c.lookup(label)
+	if err != nil {
+		return nil, err
+	}
+	if typ.Encoding != encodingInplace || typ.Base == "" {
+		return nil, fmt.Errorf("%s is not a fixed-size array", label)
+	}
+
+	return c.newArray(label, slotOf(s), typ)
+}
+
+// Slice returns the dynamic array variable label. Its
+// capacity is always equal to its length.
+func (c *Contract) Slice(label string) (ethtypes.Slice, error) {
+	s, typ, err := c.lookup(label)
+	if err != nil {
+		return nil, err
+	}
+	if typ.Encoding != encodingDynamicArray {
+		return nil, fmt.Errorf("%s is not a dynamic array", label)
+	}
+
+	arr, err := c.newArray(label, slotOf(s), typ)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Slice{Array: arr}, nil
+}
+
+// Map returns the mapping variable label.
+func (c *Contract) Map(label string) (ethtypes.Map, error) {
+	s, typ, err := c.lookup(label)
+	if err != nil {
+		return nil, err
+	}
+	if typ.Encoding != encodingMapping {
+		return nil, fmt.Errorf("%s is not a mapping", label)
+	}
+
+	keyType, valType := c.layout.Types[typ.Key], c.layout.Types[typ.Value]
+	keyGoType, err := keyType.valueType()
+	if err != nil {
+		return nil, err
+	}
+	// probe the value type with a dummy location
+	val, err := c.newCell(common.Hash{}, 0, valType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Map{
+		c:       c,
+		name:    label,
+		slot:    slotOf(s),
+		keyType: keyType,
+		valType: valType,
+		key:     keyGoType,
+		val:     val.typ,
+	}, nil
+}
+
+func (c *Contract) lookup(label string) (*Storage, *Type, error) {
+	s, err := c.layout.Lookup(label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s, c.layout.Types[s.Type], nil
+}
+
+func (c *Contract) newArray(label string, slot common.Hash, typ *Type) (*Array, error) {
+	base := c.layout.Types[typ.Base]
+	if base == nil {
+		return nil, fmt.Errorf("%s: unknown base type %s", label, typ.Base)
+	}
+	elem, err := c.newCell(common.Hash{}, 0, base)
+	if err != nil {
+		return nil, err
+	}
+
+	arr := &Array{
+		c:    c,
+		name: label,
+		slot: slot,
+		base: base,
+		typ:  elem.typ,
+	}
+	if typ.Encoding == encodingDynamicArray {
+		arr.data = crypto.Keccak256Hash(slot.Bytes())
+	} else {
+		arr.data = slot
+		n, ok := fixedLen(typ.Label)
+		if !ok {
+			return nil, fmt.Errorf("%s: cannot parse length of %s", label, typ.Label)
+		}
+		arr.fixedLen = n
+	}
+
+	return arr, nil
+}
+
+func slotOf(s *Storage) common.Hash {
+	slot, _ := new(big.Int).SetString(s.Slot, 10)
+	return common.BigToHash(slot)
+}
+
+// addSlot returns slot + n, wrapping around like the EVM does.
+func addSlot(slot common.Hash, n int) common.Hash {
+	return common.BigToHash(new(big.Int).Add(slot.Big(), big.NewInt(int64(n))))
+}
+
+// fixedLen parses the length of a fixed-size array label such as "uint8[3]".
+func fixedLen(label string) (int, bool) {
+	var n int
+	open := len(label) - 1
+	for open >= 0 && label[open] != '[' {
+		open--
+	}
+	if open < 0 {
+		return 0, false
+	}
+	if _, err := fmt.Sscanf(label[open:], "[%d]", &n); err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// cell is the location of a single value type, string or bytes value.
+type cell struct {
+	c      *Contract
+	slot   common.Hash
+	offset int
+	t      *Type
+	typ    reflect.Type
+}
+
+func (c *Contract) newCell(slot common.Hash, offset int, t *Type) (*cell, error) {
+	typ, err := t.valueType()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cell{
+		c:      c,
+		slot:   slot,
+		offset: offset,
+		t:      t,
+		typ:    typ,
+	}, nil
+}
+
+func (cl *cell) getState(slot common.Hash) common.Hash {
+	return cl.c.db.GetState(cl.c.addr, slot)
+}
+
+func (cl *cell) setState(slot, val common.Hash) {
+	cl.c.db.SetState(cl.c.addr, slot, val)
+}
+
+// raw returns the bytes of an inplace value.
+func (cl *cell) raw() []byte {
+	word := cl.getState(cl.slot)
+	end := 32 - cl.offset
+	return word[end-cl.t.size() : end]
+}
+
+func (cl *cell) setRaw(b []byte) {
+	word := cl.getState(cl.slot)
+	end := 32 - cl.offset
+	copy(word[end-cl.t.size():end], b)
+	cl.setState(cl.slot, word)
+}
+
+func (cl *cell) isZero() bool {
+	if cl.t.Encoding == encodingBytes {
+		return cl.getState(cl.slot) == common.Hash{}
+	}
+	for _, b := range cl.raw() {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (cl *cell) clear() {
+	if cl.t.Encoding == encodingBytes {
+		cl.writeBytes(nil)
+		return
+	}
+	cl.setRaw(make([]byte, cl.t.size()))
+}
+
+func (cl *cell) get(dst reflect.Value) {
+	if cl.t.Encoding == encodingBytes {
+		data, err := cl.readBytes()
+		if err != nil {
+			panic(err)
+		}
+		if cl.typ.Kind() == reflect.String {
+			dst.SetString(string(data))
+		} else {
+			dst.SetBytes(data)
+		}
+		return
+	}
+
+	decode(cl.t, cl.raw(), dst)
+}
+
+func (cl *cell) set(v reflect.Value) {
+	if cl.t.Encoding == encodingBytes {
+		switch v.Kind() {
+		case reflect.String:
+			cl.writeBytes([]byte(v.String()))
+		case reflect.Slice:
+			cl.writeBytes(v.Bytes())
+		default:
+			panic(fmt.Sprintf("cannot store %v as %s", v.Type(), cl.t.Label))
+		}
+		return
+	}
+
+	b, err := encode(cl.t, v)
+	if err != nil {
+		panic(err)
+	}
+	cl.setRaw(b)
+}
+
+// maxBytesLen bounds the length of a long string or bytes value, far above
+// what a block can store, so that a corrupt slot is not read for ever.
+const maxBytesLen = 1 << 24
+
+// longLen returns the length of the long value whose slot holds word.
+func longLen(word common.Hash) (int, error) {
+	n := new(big.Int).Rsh(word.Big(), 1)
+	if n.Cmp(big.NewInt(maxBytesLen)) > 0 {
+		return 0, fmt.Errorf("%w: length %v of long value exceeds %d", ErrCorrupt, n, maxBytesLen)
+	}
+
+	return int(n.Int64()), nil
+}
+
+// readBytes decodes a string or bytes value: short values live in the slot
+// itself with 2*length in the lowest byte, long values store 2*length+1 in
+// the slot and their data from keccak256(slot) on.
+func (cl *cell) readBytes() ([]byte, error) {
+	word := cl.getState(cl.slot)
+	if word[31]&1 == 0 {
+		n := int(word[31]) / 2
+		if n > 31 {
+			return nil, fmt.Errorf("%w: length %d of short value exceeds 31", ErrCorrupt, n)
+		}
+		return common.CopyBytes(word[:n]), nil
+	}
+
+	n, err := longLen(word)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, n)
+	start := crypto.Keccak256Hash(cl.slot.Bytes())
+	for i := 0; len(data) < n; i++ {
+		chunk := cl.getState(addSlot(start, i))
+		data = append(data, chunk[:]...)
+	}
+
+	return data[:n], nil
+}
+
+func (cl *cell) writeBytes(data []byte) {
+	// clear the data slots of a previous long value
+	if word := cl.getState(cl.slot); word[31]&1 == 1 {
+		n, err := longLen(word)
+		if err != nil {
+			panic(err)
+		}
+		start := crypto.Keccak256Hash(cl.slot.Bytes())
+		for i := 0; i*32 < n; i++ {
+			cl.setState(addSlot(start, i), common.Hash{})
+		}
+	}
+
+	if len(data) < 32 {
+		var word common.Hash
+		copy(word[:], data)
+		if len(data) > 0 {
+			word[31] = byte(len(data) * 2)
+		}
+		cl.setState(cl.slot, word)
+		return
+	}
+
+	start := crypto.Keccak256Hash(cl.slot.Bytes())
+	for i := 0; i*32 < len(data); i++ {
+		var chunk common.Hash
+		copy(chunk[:], data[i*32:])
+		cl.setState(addSlot(start, i), chunk)
+	}
+	cl.setState(cl.slot, common.BigToHash(big.NewInt(int64(len(data)*2+1))))
+}
@@ -0,0 +1,167 @@
+package layout
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// binding is a handle declared by generated code.
+type binding struct {
+	// Field is the Go name of the handle
+	Field string
+	// Label is the path of the variable in the layout
+	Label string
+	// Kind is the Contract method returning the handle
+	Kind string
+}
+
+func (b binding) Interface() string {
+	if b.Kind == "Variable" {
+		return "StateVariable"
+	}
+
+	return b.Kind
+}
+
+// bindings lists the handles of every supported variable, descending
+// into struct members. Unsupported variables are skipped.
+func (l *Layout) bindings(prefix, goPrefix string, vars []*Storage) []binding {
+	var res []binding
+	c := NewContract(nil, [20]byte{}, l)
+	for _, s := range vars {
+		b := binding{
+			Field: goPrefix + exportedName(s.Label),
+			Label: prefix + s.Label,
+		}
+		t := l.Types[s.Type]
+		switch {
+		case t.Encoding == encodingInplace && len(t.Members) > 0:
+			res = append(res, l.bindings(b.Label+".", b.Field, t.Members)...)
+			continue
+		case t.Encoding == encodingInplace && t.Base != "":
+			b.Kind = "Array"
+		case t.Encoding == encodingDynamicArray:
+			b.Kind = "Slice"
+		case t.Encoding == encodingMapping:
+			b.Kind = "Map"
+		default:
+			b.Kind = "Variable"
+		}
+
+		if err := c.bind(b); err == nil {
+			res = append(res, b)
+		}
+	}
+
+	return res
+}
+
+func (c *Contract) bind(b binding) (err error) {
+	switch b.Kind {
+	case "Array":
+		_, err = c.Array(b.Label)
+	case "Slice":
+		_, err = c.Slice(b.Label)
+	case "Map":
+		_, err = c.Map(b.Label)
+	default:
+		_, err = c.Variable(b.Label)
+	}
+
+	return err
+}
+
+func exportedName(label string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range label {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// Generate returns the source of a Go file in package pkg declaring
+// a NameStorage struct with a handle for every supported variable of
+// the layout, and a constructor binding them to a contract account.
+func (l *Layout) Generate(pkg, name string) ([]byte, error) {
+	js, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Package, Name, JSON string
+		Bindings            []binding
+	}{
+		Package:  pkg,
+		Name:     name,
+		JSON:     string(js),
+		Bindings: l.bindings("", "", l.Storage),
+	}
+
+	var buf bytes.Buffer
+	if err := layoutTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v", err)
+	}
+
+	return src, nil
+}
+
+var layoutTmpl = template.Must(template.New("layout").Parse(`// Code generated by ethtypesgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"strings"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/TheStarBoys/ethtypes/layout"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+const {{.Name}}LayoutJSON = {{printf "%q" .JSON}}
+
+// {{.Name}}Storage binds the storage variables of the {{.Name}} contract.
+type {{.Name}}Storage struct {
+{{- range .Bindings}}
+	{{.Field}} ethtypes.{{.Interface}}{{end}}
+}
+
+// New{{.Name}}Storage binds the storage of the {{.Name}} contract at addr in db.
+func New{{.Name}}Storage(db vm.StateDB, addr common.Address) (*{{.Name}}Storage, error) {
+	l, err := layout.Parse(strings.NewReader({{.Name}}LayoutJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	c := layout.NewContract(db, addr, l)
+	s := new({{.Name}}Storage)
+{{- range .Bindings}}
+	if s.{{.Field}}, err = c.{{.Kind}}("{{.Label}}"); err != nil {
+		return nil, err
+	}
+{{- end}}
+
+	return s, nil
+}
+`))
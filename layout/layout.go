@@ -0,0 +1,202 @@
+// Package layout binds ethtypes handles to the storage of an existing
+// Solidity contract, as described by the storageLayout output of solc:
+//
+//	solc --storage-layout Contract.sol
+//
+// Handles follow Solidity's storage rules (packing, keccak256 addressed
+// dynamic arrays, mappings and long strings) instead of the sha256 naming
+// scheme of ContractState, so Go code can read and write the variables of
+// the contract through the StateVariable, Array, Slice and Map interfaces.
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/TheStarBoys/ethtypes"
+)
+
+const (
+	encodingInplace      = "inplace"
+	encodingMapping      = "mapping"
+	encodingDynamicArray = "dynamic_array"
+	encodingBytes        = "bytes"
+)
+
+var (
+	ErrNotFound    = errors.New("storage variable not found")
+	ErrUnsupported = errors.New("unsupported storage type")
+	ErrCorrupt     = errors.New("corrupt storage")
+)
+
+// Layout is the storageLayout of a contract.
+type Layout struct {
+	Storage []*Storage       `json:"storage"`
+	Types   map[string]*Type `json:"types"`
+}
+
+// Storage is a state variable, or a member of a struct.
+type Storage struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+// Type describes how a type is encoded in storage.
+type Type struct {
+	Encoding      string     `json:"encoding"`
+	Label         string     `json:"label"`
+	NumberOfBytes string     `json:"numberOfBytes"`
+	Key           string     `json:"key,omitempty"`
+	Value         string     `json:"value,omitempty"`
+	Base          string     `json:"base,omitempty"`
+	Members       []*Storage `json:"members,omitempty"`
+}
+
+// Parse decodes a storageLayout JSON document. It accepts both the
+// layout itself and the per-contract output of solc --combined-json,
+// where the layout is nested under "storage-layout".
+func Parse(r io.Reader) (*Layout, error) {
+	var raw struct {
+		Layout
+		Nested *Layout `json:"storage-layout"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	l := &raw.Layout
+	if raw.Nested != nil {
+		l = raw.Nested
+	}
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *Layout) validate() error {
+	check := func(s *Storage) error {
+		if _, ok := l.Types[s.Type]; !ok {
+			return fmt.Errorf("%s: unknown type %s", s.Label, s.Type)
+		}
+		if _, ok := new(big.Int).SetString(s.Slot, 10); !ok {
+			return fmt.Errorf("%s: invalid slot %q", s.Label, s.Slot)
+		}
+		return nil
+	}
+
+	for _, s := range l.Storage {
+		if err := check(s); err != nil {
+			return err
+		}
+	}
+	for _, t := range l.Types {
+		if _, err := strconv.Atoi(t.NumberOfBytes); err != nil {
+			return fmt.Errorf("%s: invalid numberOfBytes %q", t.Label, t.NumberOfBytes)
+		}
+		for _, m := range t.Members {
+			if err := check(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Lookup finds a state variable by label. Members of struct
+// variables are addressed with dots, e.g. "config.owner".
+func (l *Layout) Lookup(label string) (*Storage, error) {
+	parts := strings.Split(label, ".")
+	var (
+		slot   = new(big.Int)
+		offset int
+		typ    string
+		vars   = l.Storage
+	)
+
+	for i, part := range parts {
+		var found *Storage
+		for _, s := range vars {
+			if s.Label == part {
+				found = s
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(parts[:i+1], "."))
+		}
+
+		rel, _ := new(big.Int).SetString(found.Slot, 10)
+		slot.Add(slot, rel)
+		offset, typ = found.Offset, found.Type
+		vars = l.Types[typ].Members
+	}
+
+	return &Storage{
+		Label:  label,
+		Offset: offset,
+		Slot:   slot.String(),
+		Type:   typ,
+	}, nil
+}
+
+func (t *Type) size() int {
+	n, _ := strconv.Atoi(t.NumberOfBytes)
+	return n
+}
+
+// valueType returns the Go type used for values of an inplace
+// value type or a string/bytes type.
+func (t *Type) valueType() (reflect.Type, error) {
+	label := t.Label
+	switch {
+	case t.Encoding == encodingBytes && label == "string":
+		return ethtypes.StringType, nil
+	case t.Encoding == encodingBytes:
+		return ethtypes.BytesType, nil
+	case t.Encoding != encodingInplace:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, label)
+	case label == "bool":
+		return ethtypes.BoolType, nil
+	case strings.HasPrefix(label, "address"), strings.HasPrefix(label, "contract "):
+		return ethtypes.AddressType, nil
+	case strings.HasPrefix(label, "enum "):
+		return ethtypes.Uint8Type, nil
+	case strings.HasPrefix(label, "uint"), strings.HasPrefix(label, "int"):
+		// odd widths such as uint24 widen to the next Go integer
+		var bits int
+		switch n := t.size(); {
+		case n > 8:
+			return ethtypes.BigIntType, nil
+		case n > 4:
+			bits = 64
+		case n > 2:
+			bits = 32
+		default:
+			bits = n * 8
+		}
+		name := fmt.Sprintf("int%d", bits)
+		if strings.HasPrefix(label, "uint") {
+			name = "u" + name
+		}
+		return ethtypes.ParseType(name)
+	case strings.HasPrefix(label, "bytes"):
+		return reflect.ArrayOf(t.size(), ethtypes.Uint8Type), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, label)
+	}
+}
+
+func (t *Type) signed() bool {
+	return strings.HasPrefix(t.Label, "int")
+}
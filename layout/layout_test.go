@@ -0,0 +1,167 @@
+package layout
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadToken(t *testing.T) *Layout {
+	f, err := os.Open(filepath.Join("testdata", "token.json"))
+	assert.Nil(t, err)
+	defer f.Close()
+
+	l, err := Parse(f)
+	assert.Nil(t, err)
+
+	return l
+}
+
+func TestContract(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	c := NewContract(statedb, addr, loadToken(t))
+	slot := func(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+
+	// packed variables share slot 1
+	decimals, err := c.Variable("decimals")
+	assert.Nil(t, err)
+	paused, _ := c.Variable("paused")
+	owner, _ := c.Variable("owner")
+	decimals.Set(uint8(18))
+	paused.Set(true)
+	owner.Set(common.HexToAddress("0xff"))
+	assert.Equal(t, common.HexToHash("0x0000000000000000000000000000000000000000ff0112"), statedb.GetState(addr, slot(1)))
+
+	var ownerVal common.Address
+	assert.True(t, owner.Get(&ownerVal))
+	assert.Equal(t, common.HexToAddress("0xff"), ownerVal)
+	paused.Del()
+	assert.False(t, paused.IsAssigned())
+	assert.True(t, decimals.IsAssigned())
+
+	// short and long strings
+	name, _ := c.Variable("name")
+	name.Set("token")
+	assert.Equal(t, byte(10), statedb.GetState(addr, slot(2))[31])
+	long := strings.Repeat("ethtypes", 5)
+	name.Set(long)
+	assert.Equal(t, slot(81), statedb.GetState(addr, slot(2)))
+	var nameVal string
+	name.Get(&nameVal)
+	assert.Equal(t, long, nameVal)
+
+	// a corrupt length fails instead of reading for ever
+	statedb.SetState(addr, slot(2), common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+	assert.PanicsWithError(t, "corrupt storage: length 57896044618658097711785492504343953926634992332820282019728792003956564819967 of long value exceeds 16777216", func() { name.Get(&nameVal) })
+	assert.Panics(t, func() { name.Set("token") })
+	statedb.SetState(addr, slot(2), common.Hash{31: 0x42})
+	assert.PanicsWithError(t, "corrupt storage: length 33 of short value exceeds 31", func() { name.Get(&nameVal) })
+	name.Set(long)
+
+	// mapping values live at keccak256(pad(key) . pad(slot))
+	balances, err := c.Map("balances")
+	assert.Nil(t, err)
+	holder := common.HexToAddress("0xabc")
+	balances.Set(holder, big.NewInt(100))
+	loc := crypto.Keccak256Hash(common.LeftPadBytes(holder.Bytes(), 32), slot(3).Bytes())
+	assert.Equal(t, slot(100), statedb.GetState(addr, loc))
+	var balance big.Int
+	assert.True(t, balances.Get(holder, &balance))
+	assert.Equal(t, big.NewInt(100), &balance)
+	assert.Panics(t, func() { balances.Set(holder, big.NewInt(-1)) })
+
+	notes, _ := c.Map("notes")
+	notes.Set("hello", "world")
+	assert.True(t, notes.Contains("hello"))
+	assert.False(t, notes.Contains("world"))
+
+	// fixed arrays pack small elements
+	small, err := c.Array("small")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, small.Len())
+	small.Set(0, uint16(1))
+	small.Set(2, uint16(3))
+	assert.Equal(t, common.HexToHash("0x000300000001"), statedb.GetState(addr, slot(4)))
+	small.Del(0)
+	assert.Equal(t, common.HexToHash("0x00030000"), statedb.GetState(addr, slot(4)))
+
+	// dynamic arrays store their data from keccak256(slot)
+	history, err := c.Slice("history")
+	assert.Nil(t, err)
+	history.Append(int64(-1), int64(2), int64(3), int64(4), int64(5))
+	assert.Equal(t, 5, history.Len())
+	data := crypto.Keccak256Hash(slot(5).Bytes())
+	assert.Equal(t, common.HexToHash("0x5"), statedb.GetState(addr, addSlot(data, 1)))
+	var last, first int64
+	history.Pop(&last)
+	history.Get(0, &first)
+	assert.Equal(t, int64(5), last)
+	assert.Equal(t, int64(-1), first)
+	assert.Equal(t, common.Hash{}, statedb.GetState(addr, addSlot(data, 1)))
+
+	// struct members are addressed with dots
+	fee, err := c.Variable("config.fee")
+	assert.Nil(t, err)
+	fee.Set(uint64(7))
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(7), 160), statedb.GetState(addr, slot(6)).Big())
+	capacity, _ := c.Variable("config.cap")
+	assert.Equal(t, slot(7), capacity.Addr())
+
+	_, err = c.Variable("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.Map("nested")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := loadToken(t).Generate("token", "Token")
+	assert.Nil(t, err)
+
+	code := string(src)
+	for _, re := range []string{
+		`TotalSupply\s+ethtypes.StateVariable`,
+		`Balances\s+ethtypes.Map`,
+		`Small\s+ethtypes.Array`,
+		`History\s+ethtypes.Slice`,
+		`ConfigFee\s+ethtypes.StateVariable`,
+		`if s.ConfigCap, err = c.Variable\("config.cap"\); err != nil {`,
+	} {
+		assert.Regexp(t, re, code)
+	}
+	// nested mappings are not supported
+	assert.NotContains(t, code, "Nested")
+}
+
+func TestGenerateQuotesJSON(t *testing.T) {
+	l := loadToken(t)
+	for _, typ := range l.Types {
+		typ.Label += " `quoted`"
+	}
+	src, err := l.Generate("token", "Token")
+	assert.Nil(t, err)
+
+	// the layout survives the round trip through the source
+	f, err := parser.ParseFile(token.NewFileSet(), "token_storage.go", src, 0)
+	assert.Nil(t, err)
+	spec := f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	assert.Equal(t, "TokenLayoutJSON", spec.Names[0].Name)
+	js, err := strconv.Unquote(spec.Values[0].(*ast.BasicLit).Value)
+	assert.Nil(t, err)
+	parsed, err := Parse(strings.NewReader(js))
+	assert.Nil(t, err)
+	assert.Equal(t, l, parsed)
+}
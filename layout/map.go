@@ -0,0 +1,64 @@
+package layout
+
+import (
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Map is a mapping whose values are value types, strings or bytes.
+type Map struct {
+	c    *Contract
+	name string
+	slot common.Hash
+
+	keyType, valType *Type
+	key, val         reflect.Type
+}
+
+var _ ethtypes.Map = (*Map)(nil)
+
+func (m *Map) Name() string {
+	return m.name
+}
+
+func (m *Map) GetKVType() (key, val reflect.Type) {
+	return m.key, m.val
+}
+
+// elem returns the value of key, stored at keccak256(h(key) . slot).
+func (m *Map) elem(key interface{}) *cell {
+	h, err := hashKey(m.keyType, reflect.ValueOf(key))
+	if err != nil {
+		panic(err)
+	}
+
+	return &cell{
+		c:    m.c,
+		slot: crypto.Keccak256Hash(h, m.slot.Bytes()),
+		t:    m.valType,
+		typ:  m.val,
+	}
+}
+
+// Get returns false if the value is zero, Solidity does not
+// distinguish missing keys from zero values.
+func (m *Map) Get(key interface{}, val interface{}) bool {
+	v := &Variable{cell: m.elem(key)}
+
+	return v.Get(val)
+}
+
+func (m *Map) Set(key, val interface{}) {
+	m.elem(key).set(reflect.ValueOf(val))
+}
+
+func (m *Map) Contains(key interface{}) bool {
+	return !m.elem(key).isZero()
+}
+
+func (m *Map) Del(key interface{}) {
+	m.elem(key).clear()
+}
@@ -0,0 +1,64 @@
+package layout
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Variable is a value type, string or bytes state variable.
+type Variable struct {
+	*cell
+	name string
+}
+
+var _ ethtypes.StateVariable = (*Variable)(nil)
+
+func (v *Variable) Name() string {
+	return v.name
+}
+
+func (v *Variable) Type() reflect.Type {
+	return v.typ
+}
+
+// Addr returns the slot of the variable.
+func (v *Variable) Addr() common.Hash {
+	return v.slot
+}
+
+// IsAssigned returns true if the value is not zero, Solidity
+// does not distinguish unassigned variables from zero values.
+func (v *Variable) IsAssigned() bool {
+	return !v.isZero()
+}
+
+func (v *Variable) Del() {
+	v.clear()
+}
+
+func (v *Variable) Set(val interface{}) {
+	v.set(reflect.ValueOf(val))
+}
+
+func (v *Variable) Get(val interface{}) bool {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr {
+		panic("val must be pointer")
+	}
+	if rv.Elem().Type() != v.typ {
+		panic(fmt.Sprintf("expect type: %v, actual type: %v", v.typ, rv.Elem().Type()))
+	}
+
+	v.get(rv.Elem())
+
+	return v.IsAssigned()
+}
+
+func (v *Variable) CopyFrom(src ethtypes.StateVariable) {
+	val := reflect.New(src.Type())
+	src.Get(val.Interface())
+	v.Set(val.Interface())
+}
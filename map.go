@@ -11,6 +11,8 @@ type BasicMap struct {
 	name    string
 	keyType reflect.Type
 	valType reflect.Type
+	// enum of the values if they are EnumValues
+	enum *Enum
 }
 
 const (
@@ -69,7 +71,8 @@ func (m *BasicMap) Name() string {
 }
 
 func (m *BasicMap) Get(key interface{}, val interface{}) bool {
-	if actual, expect := reflect.TypeOf(key).Kind(), m.keyType.Kind(); actual != expect {
+	// getElem checks full types in strict mode
+	if actual, expect := reflect.TypeOf(key).Kind(), m.keyType.Kind(); actual != expect && !m.state.strict {
 		panic(fmt.Sprintf("key not match, actual: %v, expect: %v", actual, expect))
 	}
 
@@ -82,7 +85,8 @@ func (m *BasicMap) Get(key interface{}, val interface{}) bool {
 }
 
 func (m *BasicMap) Set(key, val interface{}) {
-	if actual, expect := reflect.TypeOf(key).Kind(), m.keyType.Kind(); actual != expect {
+	// getElem checks full types in strict mode
+	if actual, expect := reflect.TypeOf(key).Kind(), m.keyType.Kind(); actual != expect && !m.state.strict {
 		panic(fmt.Sprintf("key not match, actual: %v, expect: %v", actual, expect))
 	}
 
@@ -103,17 +107,30 @@ func (m *BasicMap) GetKVType() (key, val reflect.Type) {
 	return m.keyType, m.valType
 }
 
-func (m *BasicMap) getElem(key interface{}) StateVariable {
-	bts, err := json.Marshal(key)
-	if err != nil {
-		panic("key cannot marshal")
+func (m *BasicMap) getElem(key interface{}) *BasicStateVariable {
+	if m.state.strict {
+		v := reflect.ValueOf(key)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		key = checkValue("key of", m.name, m.keyType, v).Interface()
 	}
-
-	keyStr := mapPrefix + m.name + string(bts)
+	keyStr := mapPrefix + m.name + string(encodeKey(key))
 	elem, err := GetBasicStateVariable(m.state, keyStr, m.valType)
 	if err != nil {
 		panic(fmt.Sprintf("getElem err: %v", err))
 	}
+	elem.enum = m.enum
 
 	return elem
 }
+
+// encodeKey returns the stored form of key.
+func encodeKey(key interface{}) []byte {
+	bts, err := json.Marshal(key)
+	if err != nil {
+		panic("key cannot marshal")
+	}
+
+	return bts
+}
@@ -0,0 +1,151 @@
+package ethtypes
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	merklePrefix = "merkle_"
+	// merkleDepth bounds a committed container to 2^32 elements
+	merkleDepth = 32
+)
+
+// zeroHashes[i] is the root of an empty subtree of height i,
+// an empty leaf is the zero hash.
+var zeroHashes [merkleDepth + 1]common.Hash
+
+func init() {
+	for i := 1; i <= merkleDepth; i++ {
+		zeroHashes[i] = crypto.Keccak256Hash(zeroHashes[i-1][:], zeroHashes[i-1][:])
+	}
+}
+
+// MerkleProof proves the element in Index of a committed
+// container against its root.
+type MerkleProof struct {
+	Index uint64
+	// Key is the stored form of the key of map elements,
+	// it is nil for array elements
+	Key []byte
+	// Value is the stored form of the element,
+	// it is empty if the element is not assigned
+	Value []byte
+	// Siblings are the sibling hashes from the leaf up to the root
+	Siblings []common.Hash
+}
+
+// Leaf returns the hash of the element in the tree.
+func (p *MerkleProof) Leaf() common.Hash {
+	if p.Key != nil {
+		return mapLeaf(p.Key, p.Value)
+	}
+
+	return arrayLeaf(p.Value)
+}
+
+// VerifyProof reports whether proof proves its element against root.
+func VerifyProof(root common.Hash, proof *MerkleProof) bool {
+	if len(proof.Siblings) != merkleDepth || proof.Index>>merkleDepth != 0 {
+		return false
+	}
+
+	h, index := proof.Leaf(), proof.Index
+	for _, sibling := range proof.Siblings {
+		if index&1 == 0 {
+			h = crypto.Keccak256Hash(h[:], sibling[:])
+		} else {
+			h = crypto.Keccak256Hash(sibling[:], h[:])
+		}
+		index >>= 1
+	}
+
+	return h == root
+}
+
+func arrayLeaf(value []byte) common.Hash {
+	if len(value) == 0 {
+		return common.Hash{}
+	}
+
+	return crypto.Keccak256Hash(value)
+}
+
+func mapLeaf(key, value []byte) common.Hash {
+	return crypto.Keccak256Hash(crypto.Keccak256(key), crypto.Keccak256(value))
+}
+
+// merkleTree is a binary Merkle tree of fixed depth kept in storage.
+// Nodes equal to the root of an empty subtree are not stored, so an
+// empty tree takes no storage and updating a leaf writes one node
+// per level.
+type merkleTree struct {
+	state *ContractState
+	loc   common.Hash
+}
+
+func newMerkleTree(state *ContractState, name string) *merkleTree {
+	return &merkleTree{
+		state: state,
+		loc:   state.hash(merklePrefix + name),
+	}
+}
+
+func (t *merkleTree) slot(level int, index uint64) common.Hash {
+	return sha256.Sum256(append(append([]byte(nil), t.loc[:]...),
+		[]byte(fmt.Sprintf("%d_%d", level, index))...))
+}
+
+func (t *merkleTree) node(level int, index uint64) common.Hash {
+	if h := t.state.getSlot(t.slot(level, index)); h != (common.Hash{}) {
+		return h
+	}
+
+	return zeroHashes[level]
+}
+
+func (t *merkleTree) setNode(level int, index uint64, h common.Hash) {
+	if h == zeroHashes[level] {
+		h = common.Hash{}
+	}
+	t.state.setSlot(t.slot(level, index), h)
+}
+
+func (t *merkleTree) update(index int, leaf common.Hash) {
+	if index < 0 || uint64(index)>>merkleDepth != 0 {
+		panic(ErrIndexOutOfRange)
+	}
+
+	i := uint64(index)
+	if t.node(0, i) == leaf {
+		return
+	}
+
+	h := leaf
+	for level := 0; level < merkleDepth; level++ {
+		t.setNode(level, i, h)
+		if sibling := t.node(level, i^1); i&1 == 0 {
+			h = crypto.Keccak256Hash(h[:], sibling[:])
+		} else {
+			h = crypto.Keccak256Hash(sibling[:], h[:])
+		}
+		i >>= 1
+	}
+	t.setNode(merkleDepth, 0, h)
+}
+
+func (t *merkleTree) root() common.Hash {
+	return t.node(merkleDepth, 0)
+}
+
+func (t *merkleTree) proof(index int) []common.Hash {
+	siblings := make([]common.Hash, merkleDepth)
+	for level, i := 0, uint64(index); level < merkleDepth; level, i = level+1, i>>1 {
+		siblings[level] = t.node(level, i^1)
+	}
+
+	return siblings
+}
@@ -0,0 +1,98 @@
+package ethtypes
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// StorageMeter is a vm.StateDB that counts the storage slots read and
+// written through it, and prices them like SLOAD and SSTORE under EIP-2200.
+// Logs are priced like the LOG opcodes.
+// Wrapping the state of a TypeFactory with it tells how much gas a sequence
+// of container operations costs.
+//
+// A meter with a gas limit panics with ErrGasLimit on the access which
+// exceeds it, before that access reaches the wrapped state.
+type StorageMeter struct {
+	vm.StateDB
+
+	reads, writes uint64
+	gas           uint64
+	// limit is the gas allowed, zero for no limit
+	limit uint64
+}
+
+var _ vm.StateDB = (*StorageMeter)(nil)
+
+func NewStorageMeter(db vm.StateDB) *StorageMeter {
+	return &StorageMeter{StateDB: db}
+}
+
+// SetGasLimit makes the meter panic with ErrGasLimit once more than gas
+// is charged, zero removes the limit.
+func (m *StorageMeter) SetGasLimit(gas uint64) {
+	m.limit = gas
+}
+
+// charge adds gas, it panics if that exceeds the limit.
+func (m *StorageMeter) charge(gas uint64) {
+	m.gas += gas
+	if m.limit != 0 && m.gas > m.limit {
+		panic(fmt.Errorf("%w: %d used, limit %d", ErrGasLimit, m.gas, m.limit))
+	}
+}
+
+func (m *StorageMeter) GetState(addr common.Address, key common.Hash) common.Hash {
+	m.reads++
+	m.charge(params.SloadGasEIP2200)
+
+	return m.StateDB.GetState(addr, key)
+}
+
+// SetState charges a write by the current value of the slot. Reading it is
+// neither counted nor charged: EIP-2200 prices it into the SSTORE.
+func (m *StorageMeter) SetState(addr common.Address, key, val common.Hash) {
+	m.writes++
+
+	switch current := m.StateDB.GetState(addr, key); {
+	case current == val:
+		m.charge(params.SloadGasEIP2200)
+	case current == (common.Hash{}):
+		m.charge(params.SstoreSetGasEIP2200)
+	default:
+		m.charge(params.SstoreResetGasEIP2200)
+	}
+
+	m.StateDB.SetState(addr, key, val)
+}
+
+// AddLog charges a log like the LOG opcodes.
+func (m *StorageMeter) AddLog(log *types.Log) {
+	m.charge(params.LogGas + params.LogTopicGas*uint64(len(log.Topics)) + params.LogDataGas*uint64(len(log.Data)))
+
+	m.StateDB.AddLog(log)
+}
+
+// Reads returns the number of slots read.
+func (m *StorageMeter) Reads() uint64 {
+	return m.reads
+}
+
+// Writes returns the number of slots written.
+func (m *StorageMeter) Writes() uint64 {
+	return m.writes
+}
+
+// GasUsed returns the gas charged for all reads, writes and logs.
+func (m *StorageMeter) GasUsed() uint64 {
+	return m.gas
+}
+
+// Reset clears the counters, the gas limit is kept.
+func (m *StorageMeter) Reset() {
+	m.reads, m.writes, m.gas = 0, 0, 0
+}
@@ -0,0 +1,227 @@
+package ethtypes
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// schemaVersionName is not prefixed like variables,
+// so it never collides with a variable name.
+const schemaVersionName = "schema_version"
+
+// Migration upgrades the storage of a TypeFactory
+// from one schema version to the next.
+type Migration func(tf *TypeFactory) error
+
+// Convert returns the new form of an element of the old type.
+type Convert func(old interface{}) (interface{}, error)
+
+type migration struct {
+	to uint64
+	fn Migration
+}
+
+func (t *TypeFactory) schemaVersion() *BasicStateVariable {
+	return &BasicStateVariable{
+		state: t.state,
+		name:  schemaVersionName,
+		loc:   t.state.hash(schemaVersionName),
+		typ:   Uint64Type,
+	}
+}
+
+// SchemaVersion returns the persisted schema version,
+// which is 0 until the first migration.
+func (t *TypeFactory) SchemaVersion() uint64 {
+	var version uint64
+	t.schemaVersion().Get(&version)
+	return version
+}
+
+// RegisterMigration registers fn to upgrade the storage from schema
+// version from to version to. Migrations are registered per TypeFactory,
+// a namespace starts without any.
+func (t *TypeFactory) RegisterMigration(from, to uint64, fn Migration) {
+	if from == to {
+		panic(fmt.Sprintf("migration from %d to itself", from))
+	}
+	if _, ok := t.migrations[from]; ok {
+		panic(fmt.Sprintf("duplicate migration from %d", from))
+	}
+
+	if t.migrations == nil {
+		t.migrations = make(map[uint64]migration)
+	}
+	t.migrations[from] = migration{to: to, fn: fn}
+}
+
+// Migrate runs the registered migrations in order from the persisted
+// schema version on, until it is target. The version is persisted after
+// each migration. If any migration fails or panics, all of them are
+// reverted, a panic is returned as the error. A read-only TypeFactory
+// returns ErrReadOnly unless the schema is already at target.
+func (t *TypeFactory) Migrate(target uint64) error {
+	version := t.SchemaVersion()
+	if version != target && t.state.readOnly {
+		return fmt.Errorf("migrate from %d to %d: %w", version, target, ErrReadOnly)
+	}
+
+	snapshot := t.state.db.Snapshot()
+	for steps := 0; version != target; steps++ {
+		m, ok := t.migrations[version]
+		if !ok || steps == len(t.migrations) {
+			t.state.db.RevertToSnapshot(snapshot)
+			return fmt.Errorf("%w: from %d to %d", ErrNoMigration, version, target)
+		}
+
+		if err := m.run(t); err != nil {
+			t.state.db.RevertToSnapshot(snapshot)
+			return fmt.Errorf("migrate from %d to %d: %w", version, m.to, err)
+		}
+		version = m.to
+		t.schemaVersion().Set(version)
+	}
+
+	return nil
+}
+
+// run runs the migration, containers panic on misuse
+// so a panic is returned as an error.
+func (m migration) run(t *TypeFactory) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if perr, ok := r.(error); ok {
+				err = perr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	return m.fn(t)
+}
+
+// MigrateVariable rewrites the variable called name from oldType
+// to newType with fn. An unassigned variable is left as it is.
+func (t *TypeFactory) MigrateVariable(name string, oldType, newType reflect.Type, fn Convert) error {
+	v, err := GetBasicStateVariable(t.state, name, oldType)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateElem(v, newType, fn); err != nil {
+		return err
+	}
+
+	return t.retype(name, newType)
+}
+
+// MigrateArray rewrites every assigned element of the array called name
+// from oldType to newType with fn.
+func (t *TypeFactory) MigrateArray(name string, oldType, newType reflect.Type, fn Convert) error {
+	arr, err := GetBasicArray(t.state, name, oldType)
+	if err != nil {
+		return err
+	}
+	_, committed, err := t.Kind(name)
+	if err != nil {
+		return err
+	}
+	if committed {
+		arr.commit()
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		if err := migrateElem(arr.getElem(i), newType, fn); err != nil {
+			return err
+		}
+	}
+
+	return t.retype(name, newType)
+}
+
+// MigrateSlice rewrites every assigned element of the slice called name
+// from oldType to newType with fn.
+func (t *TypeFactory) MigrateSlice(name string, oldType, newType reflect.Type, fn Convert) error {
+	// the elements are stored in an array up to the capacity
+	return t.MigrateArray(name, oldType, newType, fn)
+}
+
+// MigrateMap rewrites the values of keys in the map called name from
+// oldType to newType with fn. The keys of a map cannot be listed,
+// use MigrateIterableMap to rewrite every value, which committed
+// iterable maps require.
+func (t *TypeFactory) MigrateMap(name string, keyType, oldType, newType reflect.Type, keys []interface{}, fn Convert) error {
+	m, err := GetBasicMap(t.state, name, keyType, oldType)
+	if err != nil {
+		return err
+	}
+	_, committed, err := t.Kind(name)
+	if err != nil {
+		return err
+	}
+	if committed {
+		// its leaves would be left stale
+		return fmt.Errorf("%w: %s is committed, use MigrateIterableMap", ErrTypeMismatch, name)
+	}
+
+	for _, key := range keys {
+		if err := migrateElem(m.getElem(key), newType, fn); err != nil {
+			return err
+		}
+	}
+
+	return t.retype(name, newType)
+}
+
+// MigrateIterableMap rewrites every value of the iterable map
+// called name from oldType to newType with fn.
+func (t *TypeFactory) MigrateIterableMap(name string, keyType, oldType, newType reflect.Type, fn Convert) error {
+	im, err := GetBasicIterableMap(t.state, name, keyType, oldType)
+	if err != nil {
+		return err
+	}
+	_, committed, err := t.Kind(name)
+	if err != nil {
+		return err
+	}
+	if committed {
+		im.commit()
+	}
+
+	for i := 0; i < im.Len(); i++ {
+		key := reflect.New(keyType)
+		im.keys.Get(i, key.Interface())
+		if err := migrateElem(im.data.getElem(key.Elem().Interface()), newType, fn); err != nil {
+			return err
+		}
+		if im.tree != nil {
+			im.update(i, key.Elem().Interface())
+		}
+	}
+
+	return t.retype(name, newType)
+}
+
+// migrateElem rewrites v as newType with fn if it is assigned.
+func migrateElem(v *BasicStateVariable, newType reflect.Type, fn Convert) error {
+	if !v.IsAssigned() {
+		return nil
+	}
+
+	old := reflect.New(v.typ)
+	v.Get(old.Interface())
+	val, err := fn(old.Elem().Interface())
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", v.name, err)
+	}
+
+	for count := 3; count > 0 && newType.Kind() == reflect.Ptr; count-- {
+		newType = newType.Elem()
+	}
+	migrated := *v
+	migrated.typ = newType
+	migrated.Set(val)
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package ethtypes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type PersonV2 struct {
+	Name  string
+	Age   uint8
+	Email string
+}
+
+func TestMigrate(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	personType := reflect.TypeOf(Person{})
+	personV2Type := reflect.TypeOf(PersonV2{})
+	tf.NewSlice("people", 0, 0, personType).Append(Person{Name: "alice", Age: 20}, Person{Name: "bob", Age: 30})
+	scores := tf.NewCommittedIterableMap("scores", StringType, IntType)
+	scores.Set("alice", 1)
+	scores.Set("bob", 2)
+
+	assert.Equal(t, uint64(0), tf.SchemaVersion())
+	tf.RegisterMigration(0, 1, func(tf *TypeFactory) error {
+		return tf.MigrateSlice("people", personType, personV2Type, func(old interface{}) (interface{}, error) {
+			p := old.(Person)
+			return PersonV2{Name: p.Name, Age: p.Age, Email: p.Name + "@example.com"}, nil
+		})
+	})
+	tf.RegisterMigration(1, 2, func(tf *TypeFactory) error {
+		return tf.MigrateIterableMap("scores", StringType, IntType, Int64Type, func(old interface{}) (interface{}, error) {
+			return int64(old.(int) * 10), nil
+		})
+	})
+	assert.Panics(t, func() { tf.RegisterMigration(1, 3, nil) })
+
+	assert.NoError(t, tf.Migrate(2))
+	assert.Equal(t, uint64(2), tf.SchemaVersion())
+
+	var p PersonV2
+	tf.GetSlice("people", 0, 0, personV2Type).Get(1, &p)
+	assert.Equal(t, PersonV2{Name: "bob", Age: 30, Email: "bob@example.com"}, p)
+
+	var score int64
+	migrated := tf.GetCommittedIterableMap("scores", StringType, Int64Type)
+	migrated.Get("bob", &score)
+	assert.Equal(t, int64(20), score)
+	proof, err := migrated.Proof("bob")
+	assert.NoError(t, err)
+	assert.True(t, VerifyProof(migrated.Root(), proof))
+
+	// a failed migration reverts all of them
+	failed := errors.New("failed")
+	tf.RegisterMigration(2, 3, func(tf *TypeFactory) error {
+		return tf.MigrateVariable("missing", IntType, StringType, nil)
+	})
+	tf.RegisterMigration(3, 4, func(tf *TypeFactory) error {
+		tf.GetSlice("people", 0, 0, personV2Type).Pop(&p)
+		return failed
+	})
+	assert.ErrorIs(t, tf.Migrate(4), failed)
+	assert.Equal(t, uint64(2), tf.SchemaVersion())
+	assert.Equal(t, 2, tf.GetSlice("people", 0, 0, personV2Type).Len())
+
+	// so does a panicking one
+	ns := tf.Namespace("panics")
+	ns.NewVariable("stake", 1)
+	ns.RegisterMigration(0, 1, func(tf *TypeFactory) error {
+		tf.GetVariable("stake", IntType).Set(2)
+		tf.GetVariable("stake", StringType)
+		return nil
+	})
+	assert.ErrorIs(t, ns.Migrate(1), ErrTypeMismatch)
+	assert.Equal(t, uint64(0), ns.SchemaVersion())
+	var stake int
+	ns.GetVariable("stake", IntType).Get(&stake)
+	assert.Equal(t, 1, stake)
+
+	// a read-only factory cannot migrate, but may check the version
+	ro, _ := NewReadOnlyTypeFactory(state, common.HexToAddress("123"))
+	ro.RegisterMigration(2, 3, func(tf *TypeFactory) error { return nil })
+	assert.NoError(t, ro.Migrate(2))
+	assert.ErrorIs(t, ro.Migrate(3), ErrReadOnly)
+	assert.Equal(t, uint64(2), ro.SchemaVersion())
+
+	// namespaces have their own versions and migrations
+	assert.Equal(t, uint64(0), tf.Namespace("staking").SchemaVersion())
+	assert.ErrorIs(t, tf.Namespace("staking").Migrate(1), ErrNoMigration)
+}
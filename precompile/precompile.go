@@ -0,0 +1,324 @@
+// Package precompile adapts Go functions to vm.PrecompiledContract,
+// dispatching calls by ABI method selector and giving every handler a
+// TypeFactory over the state the contract runs against.
+//
+// A handler of the ABI method
+//
+//	function transfer(address to, uint256 amount) returns (bool)
+//
+// is a function taking a *Context followed by the decoded inputs, and
+// returning the outputs followed by an error:
+//
+//	func(ctx *precompile.Context, to common.Address, amount *big.Int) (bool, error)
+//
+// A non-nil error reverts the call with the error message as reason.
+//
+// The EVM asks for the gas of a call before running it, so every handler
+// runs twice: RequiredGas meters it against the state and reverts it, then
+// Run executes it. Handlers must therefore be deterministic and only have
+// effects through Context.StateDB, the first run must not be observable.
+//
+// RequiredGas aborts a handler once its storage costs more than the gas
+// limit of the contract, DefaultGasLimit unless set by WithGasLimit, and
+// prices the call at math.MaxUint64 so that it runs out of gas. Only
+// storage is metered: handlers must not loop without touching it.
+package precompile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultGasLimit is the gas RequiredGas lets the storage of a call cost.
+const DefaultGasLimit = 30000000
+
+var (
+	ErrNoMethod     = errors.New("no method with this selector")
+	ErrInvalidInput = errors.New("invalid input")
+
+	contextType = reflect.TypeOf((*Context)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+
+	// revertSelector is the selector of Error(string), which
+	// Solidity uses to encode revert reasons.
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+)
+
+// Context is passed to every handler.
+type Context struct {
+	// Factory declares and opens the state of the contract.
+	Factory *ethtypes.TypeFactory
+	// StateDB is the state the call runs against.
+	StateDB vm.StateDB
+	// Address is the address of the contract.
+	Address common.Address
+	// Caller is the account calling the contract.
+	Caller common.Address
+	// Method is the method being called.
+	Method *abi.Method
+}
+
+// callFunc runs a method with its decoded inputs and returns its outputs.
+type callFunc func(ctx *Context, args []interface{}) ([]interface{}, error)
+
+type method struct {
+	abi  abi.Method
+	call callFunc
+}
+
+// Contract is the definition of a precompiled contract. Bind it to
+// a state to obtain a vm.PrecompiledContract.
+type Contract struct {
+	abi     abi.ABI
+	addr    common.Address
+	baseGas uint64
+	// gasLimit caps the storage gas metered by RequiredGas
+	gasLimit uint64
+	// factoryOpts configure the TypeFactory of every call
+	factoryOpts []ethtypes.Option
+	// methods are keyed by selector
+	methods map[string]*method
+	publics []public
+}
+
+type Option func(*Contract)
+
+// WithBaseGas charges gas on every call, on top of the storage it accesses.
+func WithBaseGas(gas uint64) Option {
+	return func(c *Contract) {
+		c.baseGas = gas
+	}
+}
+
+// WithGasLimit sets the gas the storage of a call may cost before
+// RequiredGas aborts it, zero removes the limit.
+func WithGasLimit(gas uint64) Option {
+	return func(c *Contract) {
+		c.gasLimit = gas
+	}
+}
+
+// WithFactoryOptions configures the TypeFactory handlers receive,
+// such as with ethtypes.WithStrictTypes.
+func WithFactoryOptions(opts ...ethtypes.Option) Option {
+	return func(c *Contract) {
+		c.factoryOpts = append(c.factoryOpts, opts...)
+	}
+}
+
+// New creates a contract at addr from its ABI JSON and a handler per
+// method name. Every method of the ABI must have a handler, except for
+// the getters of variables made public with the Public options, which
+// are added to the ABI.
+func New(abiJSON string, addr common.Address, handlers map[string]interface{}, opts ...Option) (*Contract, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Contract{
+		abi:      parsed,
+		addr:     addr,
+		gasLimit: DefaultGasLimit,
+		methods:  make(map[string]*method),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for name, m := range parsed.Methods {
+		h, ok := handlers[name]
+		if !ok {
+			return nil, fmt.Errorf("no handler for method %s", name)
+		}
+		call, err := newCallFunc(m, h)
+		if err != nil {
+			return nil, fmt.Errorf("handler of %s: %v", name, err)
+		}
+		c.methods[string(m.ID)] = &method{abi: m, call: call}
+	}
+	for name := range handlers {
+		if _, ok := parsed.Methods[name]; !ok {
+			return nil, fmt.Errorf("handler %s has no method in the ABI", name)
+		}
+	}
+	for _, p := range c.publics {
+		if err := c.addGetter(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// newCallFunc checks that handler fits m and wraps it.
+func newCallFunc(m abi.Method, handler interface{}) (callFunc, error) {
+	fn := reflect.ValueOf(handler)
+	typ := fn.Type()
+	if typ.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%v is not a function", typ)
+	}
+	if typ.NumIn() != len(m.Inputs)+1 || typ.In(0) != contextType {
+		return nil, fmt.Errorf("want *Context and %d inputs, have %v", len(m.Inputs), typ)
+	}
+	if typ.NumOut() != len(m.Outputs)+1 || typ.Out(typ.NumOut()-1) != errorType {
+		return nil, fmt.Errorf("want %d outputs and an error, have %v", len(m.Outputs), typ)
+	}
+
+	return func(ctx *Context, args []interface{}) ([]interface{}, error) {
+		in := make([]reflect.Value, len(args)+1)
+		in[0] = reflect.ValueOf(ctx)
+		for i, arg := range args {
+			v, err := convert(arg, typ.In(i+1))
+			if err != nil {
+				return nil, fmt.Errorf("%w: argument %s: %v", ErrInvalidInput, m.Inputs[i].Name, err)
+			}
+			in[i+1] = v
+		}
+
+		out := fn.Call(in)
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return nil, err
+		}
+
+		outs := make([]interface{}, len(out)-1)
+		for i := range outs {
+			outs[i] = out[i].Interface()
+		}
+		return outs, nil
+	}, nil
+}
+
+func convert(arg interface{}, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(arg)
+	switch {
+	case v.Type().AssignableTo(typ):
+		return v, nil
+	case v.Type().ConvertibleTo(typ):
+		return v.Convert(typ), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot use %v as %v", v.Type(), typ)
+	}
+}
+
+// ABI returns the ABI of the contract.
+func (c *Contract) ABI() abi.ABI {
+	return c.abi
+}
+
+// Address returns the address of the contract.
+func (c *Contract) Address() common.Address {
+	return c.addr
+}
+
+// Bind returns the contract running against db on behalf of caller. Its
+// RequiredGas runs the handler of the call once more before Run, see the
+// package documentation.
+func (c *Contract) Bind(db vm.StateDB, caller common.Address) vm.PrecompiledContract {
+	return &boundContract{
+		Contract: c,
+		db:       db,
+		caller:   caller,
+	}
+}
+
+// run dispatches input and returns the ABI encoded outputs. Failures are
+// returned as the revert data of the Error(string) reason and an error.
+func (c *Contract) run(db vm.StateDB, caller common.Address, input []byte) (ret []byte, err error) {
+	outs, m, err := c.call(db, caller, input)
+	if err == nil {
+		ret, err = m.abi.Outputs.Pack(outs...)
+	}
+	if err != nil {
+		return revertReason(err), vm.ErrExecutionReverted
+	}
+
+	return ret, nil
+}
+
+func (c *Contract) call(db vm.StateDB, caller common.Address, input []byte) (outs []interface{}, m *method, err error) {
+	if len(input) < 4 {
+		return nil, nil, ErrNoMethod
+	}
+	m, ok := c.methods[string(input[:4])]
+	if !ok {
+		return nil, nil, ErrNoMethod
+	}
+
+	args, err := m.abi.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	tf, err := ethtypes.NewTypeFactory(db, c.addr, c.factoryOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// containers panic on misuse, which must not crash the node
+	defer func() {
+		if r := recover(); r != nil {
+			if perr, ok := r.(error); ok {
+				err = perr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	outs, err = m.call(&Context{
+		Factory: tf,
+		StateDB: db,
+		Address: c.addr,
+		Caller:  caller,
+		Method:  &m.abi,
+	}, args)
+
+	return outs, m, err
+}
+
+func revertReason(err error) []byte {
+	typ, _ := abi.NewType("string", "", nil)
+	data, _ := abi.Arguments{{Type: typ}}.Pack(err.Error())
+
+	return append(append([]byte(nil), revertSelector...), data...)
+}
+
+type boundContract struct {
+	*Contract
+	db     vm.StateDB
+	caller common.Address
+}
+
+var _ vm.PrecompiledContract = (*boundContract)(nil)
+
+// RequiredGas runs the call against a snapshot of the state, metering
+// its storage accesses, and reverts it. The call runs again in Run, the
+// handlers of a contract are deterministic so the price matches. A call
+// exceeding the gas limit is aborted and costs math.MaxUint64.
+func (b *boundContract) RequiredGas(input []byte) uint64 {
+	meter := ethtypes.NewStorageMeter(b.db)
+	meter.SetGasLimit(b.gasLimit)
+	snapshot := b.db.Snapshot()
+	b.call(meter, b.caller, input)
+	b.db.RevertToSnapshot(snapshot)
+
+	if b.gasLimit != 0 && meter.GasUsed() > b.gasLimit {
+		return math.MaxUint64
+	}
+
+	return b.baseGas + meter.GasUsed()
+}
+
+func (b *boundContract) Run(input []byte) ([]byte, error) {
+	return b.run(b.db, b.caller, input)
+}
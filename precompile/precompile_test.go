@@ -0,0 +1,179 @@
+package precompile
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+const registryABI = `[
+	{"type": "function", "name": "set", "inputs": [{"name": "key", "type": "string"}, {"name": "val", "type": "uint256"}], "outputs": []},
+	{"type": "function", "name": "get", "stateMutability": "view", "inputs": [{"name": "key", "type": "string"}], "outputs": [{"name": "", "type": "uint256"}]}
+]`
+
+var admin = common.HexToAddress("0xad")
+
+func newRegistry(t *testing.T) *Contract {
+	values := func(ctx *Context) ethtypes.Map {
+		return ctx.Factory.GetMap("values", ethtypes.StringType, ethtypes.BigIntType)
+	}
+
+	c, err := New(registryABI, common.HexToAddress("0x100"), map[string]interface{}{
+		"set": func(ctx *Context, key string, val *big.Int) error {
+			if ctx.Caller != admin {
+				return errors.New("caller is not the admin")
+			}
+			values(ctx).Set(key, val)
+			return nil
+		},
+		"get": func(ctx *Context, key string) (*big.Int, error) {
+			val := new(big.Int)
+			values(ctx).Get(key, val)
+			return val, nil
+		},
+	}, WithBaseGas(100))
+	assert.Nil(t, err)
+
+	return c
+}
+
+func TestContract(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	c := newRegistry(t)
+	parsed := c.ABI()
+
+	set, _ := parsed.Pack("set", "answer", big.NewInt(42))
+	get, _ := parsed.Pack("get", "answer")
+
+	// gas is metered on a dry run which leaves no trace
+	p := c.Bind(statedb, admin)
+	// a new value reads the descriptor of the map, and writes its length and one chunk
+	assert.Equal(t, 100+params.SloadGasEIP2200+2*params.SstoreSetGasEIP2200, p.RequiredGas(set))
+	ret, err := p.Run(get)
+	assert.Nil(t, err)
+	assert.Equal(t, common.Hash{}.Bytes(), ret)
+
+	_, err = p.Run(set)
+	assert.Nil(t, err)
+	ret, err = p.Run(get)
+	assert.Nil(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(42)).Bytes(), ret)
+
+	// errors revert with a reason
+	ret, err = c.Bind(statedb, common.HexToAddress("0x1")).Run(set)
+	assert.Equal(t, vm.ErrExecutionReverted, err)
+	reason, _ := abi.UnpackRevert(ret)
+	assert.Equal(t, "caller is not the admin", reason)
+
+	ret, err = p.Run([]byte{1, 2, 3, 4})
+	assert.Equal(t, vm.ErrExecutionReverted, err)
+	reason, _ = abi.UnpackRevert(ret)
+	assert.Equal(t, ErrNoMethod.Error(), reason)
+}
+
+func TestGasLimit(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("0x100")
+	c, err := New(`[{"type": "function", "name": "spin", "inputs": [], "outputs": []}]`, addr, map[string]interface{}{
+		"spin": func(ctx *Context) error {
+			ids := ctx.Factory.NewSlice("ids", 0, 0, ethtypes.Uint64Type)
+			for i := uint64(0); ; i++ {
+				ids.Append(i)
+			}
+		},
+	}, WithGasLimit(1000000))
+	assert.Nil(t, err)
+	spin, _ := c.ABI().Pack("spin")
+
+	// a runaway handler is aborted and runs out of gas
+	assert.Equal(t, uint64(math.MaxUint64), c.Bind(statedb, admin).RequiredGas(spin))
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+	kind, _, err := tf.Kind("ids")
+	assert.NoError(t, err)
+	assert.Empty(t, kind)
+}
+
+func TestNew(t *testing.T) {
+	addr := common.HexToAddress("0x100")
+	_, err := New(registryABI, addr, map[string]interface{}{
+		"set": func(ctx *Context, key string, val *big.Int) error { return nil },
+	})
+	assert.EqualError(t, err, "no handler for method get")
+
+	_, err = New(registryABI, addr, map[string]interface{}{
+		"set": func(ctx *Context, key string) error { return nil },
+		"get": func(ctx *Context, key string) (*big.Int, error) { return nil, nil },
+	})
+	assert.Error(t, err)
+}
+
+func TestPublic(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("0x100")
+
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+	tf.NewVariable("owner", admin)
+	scores := tf.NewArray("scores", 3, ethtypes.IntType)
+	scores.Set(1, 7)
+	tf.NewStringSlice("names", 2, 2, []string{"alice", "bob"})
+	tf.NewMap("balances", ethtypes.AddressType, ethtypes.BigIntType).Set(admin, big.NewInt(100))
+	tf.NewVariable("rate", ethtypes.NewDecimal(big.NewInt(-150), 2))
+	tf.NewIterableMap("votes", ethtypes.StringType, ethtypes.Uint32Type).Set("yes", uint32(3))
+	tf.NewCommittedIterableMap("stakes", ethtypes.AddressType, ethtypes.BigIntType).Set(admin, big.NewInt(5))
+
+	c, err := New(`[]`, addr, nil,
+		PublicVariable("owner", ethtypes.AddressType),
+		PublicArray("scores", ethtypes.IntType),
+		PublicSlice("names", ethtypes.StringType),
+		PublicMap("balances", ethtypes.AddressType, ethtypes.BigIntType),
+		PublicVariable("rate", ethtypes.DecimalType),
+		PublicMap("votes", ethtypes.StringType, ethtypes.Uint32Type),
+		PublicMap("stakes", ethtypes.AddressType, ethtypes.BigIntType),
+	)
+	assert.Nil(t, err)
+	parsed := c.ABI()
+	p := c.Bind(statedb, admin)
+
+	call := func(name string, args ...interface{}) []interface{} {
+		input, err := parsed.Pack(name, args...)
+		assert.Nil(t, err)
+		ret, err := p.Run(input)
+		assert.Nil(t, err)
+		outs, err := parsed.Unpack(name, ret)
+		assert.Nil(t, err)
+		return outs
+	}
+
+	assert.Equal(t, []interface{}{admin}, call("owner"))
+	assert.Equal(t, []interface{}{int64(7)}, call("scores", big.NewInt(1)))
+	assert.Equal(t, []interface{}{"bob"}, call("names", big.NewInt(1)))
+	assert.Equal(t, []interface{}{big.NewInt(100)}, call("balances", admin))
+	assert.Equal(t, 0, call("balances", common.HexToAddress("0x1"))[0].(*big.Int).Sign())
+	// decimals are returned unscaled
+	assert.Equal(t, []interface{}{big.NewInt(-150)}, call("rate"))
+	// iterable maps are served like maps
+	assert.Equal(t, []interface{}{uint32(3)}, call("votes", "yes"))
+	assert.Equal(t, []interface{}{big.NewInt(5)}, call("stakes", admin))
+
+	input, _ := parsed.Pack("names", big.NewInt(2))
+	ret, err := p.Run(input)
+	assert.Equal(t, vm.ErrExecutionReverted, err)
+	reason, _ := abi.UnpackRevert(ret)
+	assert.Equal(t, ethtypes.ErrIndexOutOfRange.Error(), reason)
+
+	_, err = New(`[]`, addr, nil, PublicVariable("price", ethtypes.Float64Type))
+	assert.ErrorIs(t, err, ethtypes.ErrUnknownType)
+}
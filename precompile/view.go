@@ -0,0 +1,221 @@
+package precompile
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+const (
+	publicVariable = iota
+	publicArray
+	publicSlice
+	publicMap
+)
+
+// public is a variable or container exposed through a getter.
+type public struct {
+	kind             int
+	name             string
+	keyType, valType reflect.Type
+}
+
+// PublicVariable exposes the state variable name through the
+// view method name() returning its value.
+func PublicVariable(name string, typ reflect.Type) Option {
+	return func(c *Contract) {
+		c.publics = append(c.publics, public{kind: publicVariable, name: name, valType: typ})
+	}
+}
+
+// PublicArray exposes the array name through the view method
+// name(uint256 index) returning the element at index.
+func PublicArray(name string, elemType reflect.Type) Option {
+	return func(c *Contract) {
+		c.publics = append(c.publics, public{kind: publicArray, name: name, valType: elemType})
+	}
+}
+
+// PublicSlice exposes the slice name like PublicArray.
+func PublicSlice(name string, elemType reflect.Type) Option {
+	return func(c *Contract) {
+		c.publics = append(c.publics, public{kind: publicSlice, name: name, valType: elemType})
+	}
+}
+
+// PublicMap exposes the map or iterable map name through the view
+// method name(key) returning the value of key, or the zero value.
+func PublicMap(name string, keyType, valType reflect.Type) Option {
+	return func(c *Contract) {
+		c.publics = append(c.publics, public{kind: publicMap, name: name, keyType: keyType, valType: valType})
+	}
+}
+
+// addGetter declares the view method of p in the ABI of c.
+func (c *Contract) addGetter(p public) error {
+	if _, ok := c.abi.Methods[p.name]; ok {
+		return fmt.Errorf("getter %s conflicts with a method of the ABI", p.name)
+	}
+
+	valType, err := abiType(p.valType)
+	if err != nil {
+		return fmt.Errorf("getter %s: %w", p.name, err)
+	}
+
+	var (
+		inputs abi.Arguments
+		call   callFunc
+	)
+	switch p.kind {
+	case publicVariable:
+		call = func(ctx *Context, args []interface{}) ([]interface{}, error) {
+			val := reflect.New(p.valType)
+			ctx.Factory.GetVariable(p.name, p.valType).Get(val.Interface())
+			return []interface{}{toABI(val, valType)}, nil
+		}
+	case publicArray, publicSlice:
+		indexType, _ := abi.NewType("uint256", "", nil)
+		inputs = abi.Arguments{{Name: "index", Type: indexType}}
+		call = func(ctx *Context, args []interface{}) ([]interface{}, error) {
+			index := args[0].(*big.Int)
+			if !index.IsInt64() {
+				return nil, ethtypes.ErrIndexOutOfRange
+			}
+			var arr ethtypes.Array
+			if p.kind == publicArray {
+				arr = ctx.Factory.GetArray(p.name, 0, p.valType)
+			} else {
+				arr = ctx.Factory.GetSlice(p.name, 0, 0, p.valType)
+			}
+			val := reflect.New(p.valType)
+			arr.Get(int(index.Int64()), val.Interface())
+			return []interface{}{toABI(val, valType)}, nil
+		}
+	default:
+		keyType, err := abiType(p.keyType)
+		if err != nil {
+			return fmt.Errorf("getter %s: %w", p.name, err)
+		}
+		inputs = abi.Arguments{{Name: "key", Type: keyType}}
+		call = func(ctx *Context, args []interface{}) ([]interface{}, error) {
+			key, err := fromABI(args[0], p.keyType)
+			if err != nil {
+				return nil, fmt.Errorf("%w: key: %v", ErrInvalidInput, err)
+			}
+			m, err := getMap(ctx.Factory, p)
+			if err != nil {
+				return nil, err
+			}
+			val := reflect.New(p.valType)
+			m.Get(key, val.Interface())
+			return []interface{}{toABI(val, valType)}, nil
+		}
+	}
+
+	outputs := abi.Arguments{{Name: "", Type: valType}}
+	m := abi.NewMethod(p.name, p.name, abi.Function, "view", true, false, inputs, outputs)
+	c.abi.Methods[p.name] = m
+	c.methods[string(m.ID)] = &method{abi: m, call: call}
+
+	return nil
+}
+
+// getMap opens the map of p as the kind of map it was created as,
+// so reading an iterable map passes its descriptor check.
+func getMap(tf *ethtypes.TypeFactory, p public) (ethtypes.Map, error) {
+	kind, committed, err := tf.Kind(p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case kind == ethtypes.KindIterableMap && committed:
+		return tf.GetCommittedIterableMap(p.name, p.keyType, p.valType), nil
+	case kind == ethtypes.KindIterableMap:
+		return tf.GetIterableMap(p.name, p.keyType, p.valType), nil
+	default:
+		return tf.GetMap(p.name, p.keyType, p.valType), nil
+	}
+}
+
+// abiType returns the ABI type of values of the Go type typ.
+func abiType(typ reflect.Type) (abi.Type, error) {
+	var name string
+	switch {
+	case typ == ethtypes.AddressType:
+		name = "address"
+	case typ == ethtypes.BigIntType:
+		name = "uint256"
+	case typ == ethtypes.DecimalType:
+		// the unscaled value, the scale is known to callers
+		name = "int256"
+	case typ == ethtypes.BytesType:
+		name = "bytes"
+	case typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8 && typ.Len() <= 32:
+		name = fmt.Sprintf("bytes%d", typ.Len())
+	case typ.Kind() == reflect.Slice:
+		elem, err := abiType(typ.Elem())
+		if err != nil {
+			return abi.Type{}, err
+		}
+		name = elem.String() + "[]"
+	default:
+		switch typ.Kind() {
+		case reflect.Bool, reflect.String:
+			name = typ.Kind().String()
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			name = typ.Kind().String()
+		case reflect.Int:
+			name = "int64"
+		case reflect.Uint:
+			name = "uint64"
+		default:
+			return abi.Type{}, fmt.Errorf("%w: %v", ethtypes.ErrUnknownType, typ)
+		}
+	}
+
+	return abi.NewType(name, "", nil)
+}
+
+// toABI converts the value ptr points to into the Go type the ABI
+// packs as t, e.g. int to int64 or big.Int to *big.Int.
+func toABI(ptr reflect.Value, t abi.Type) interface{} {
+	v := ptr.Elem()
+	goType := t.GetType()
+	switch {
+	case v.Type() == ethtypes.DecimalType:
+		return v.Interface().(ethtypes.Decimal).Unscaled()
+	case v.Type() == goType:
+		return v.Interface()
+	case ptr.Type() == goType:
+		return ptr.Interface()
+	case v.Kind() == reflect.Slice && t.Elem != nil:
+		res := reflect.MakeSlice(goType, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(v.Index(i))
+			res.Index(i).Set(reflect.ValueOf(toABI(elem, *t.Elem)))
+		}
+		return res.Interface()
+	default:
+		return v.Convert(goType).Interface()
+	}
+}
+
+// fromABI converts a decoded ABI value into typ.
+func fromABI(arg interface{}, typ reflect.Type) (interface{}, error) {
+	if b, ok := arg.(*big.Int); ok && typ == ethtypes.BigIntType {
+		return *b, nil
+	}
+
+	v, err := convert(arg, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Interface(), nil
+}
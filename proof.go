@@ -0,0 +1,214 @@
+package ethtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Prover is a StateDB which can prove accounts and storage slots
+// like eth_getProof, such as *state.StateDB. The proofs are against
+// the root of its last Commit or IntermediateRoot.
+type Prover interface {
+	vm.StateDB
+	GetProof(addr common.Address) ([][]byte, error)
+	GetStorageProof(addr common.Address, key common.Hash) ([][]byte, error)
+}
+
+// StorageProof proves the value of a variable or map entry,
+// it holds a proof of every slot ContractState stores it in.
+type StorageProof struct {
+	Address      common.Address
+	AccountProof [][]byte
+	// Slots holds the slot of a fixed-size value such as a common.Hash,
+	// or if it is zero, the length slot followed by the data chunks,
+	// and if the length is zero too, the assigned slot of a zero value
+	Slots []SlotProof
+}
+
+// SlotProof is the Merkle-Patricia proof of a storage slot.
+type SlotProof struct {
+	Key   common.Hash
+	Value common.Hash
+	Proof [][]byte
+}
+
+// ProveVariable proves the variable called name of the contract in addr.
+// Names are resolved in the root namespace of the contract.
+func ProveVariable(db Prover, addr common.Address, name string) (*StorageProof, error) {
+	return prove(db, addr, variableLoc(name))
+}
+
+// ProveMapEntry proves the value of key in the map called name.
+func ProveMapEntry(db Prover, addr common.Address, name string, key interface{}) (*StorageProof, error) {
+	return prove(db, addr, mapEntryLoc(name, key))
+}
+
+// VerifyVariable verifies proof against the state root, and decodes the
+// proven value of the variable called name into val, which must be a
+// pointer. It reports whether the variable is assigned.
+func VerifyVariable(root common.Hash, addr common.Address, name string, proof *StorageProof, val interface{}) (bool, error) {
+	return verify(root, addr, variableLoc(name), proof, val)
+}
+
+// VerifyMapEntry verifies proof like VerifyVariable,
+// for the value of key in the map called name.
+func VerifyMapEntry(root common.Hash, addr common.Address, name string, key interface{}, proof *StorageProof, val interface{}) (bool, error) {
+	return verify(root, addr, mapEntryLoc(name, key), proof, val)
+}
+
+func mapEntryLoc(name string, key interface{}) common.Hash {
+	return variableLoc(mapPrefix + name + string(encodeKey(key)))
+}
+
+func prove(db Prover, addr common.Address, loc common.Hash) (*StorageProof, error) {
+	accountProof, err := db.GetProof(addr)
+	if err != nil {
+		return nil, fmt.Errorf("prove account %v: %w", addr, err)
+	}
+	p := &StorageProof{
+		Address:      addr,
+		AccountProof: accountProof,
+	}
+
+	// readValue reads the same slots as BasicStateVariable,
+	// the account proof alone proves a missing account empty
+	exist := db.Exist(addr)
+	var proveErr error
+	readValue(loc, func(key common.Hash) common.Hash {
+		slot := SlotProof{Key: key, Value: db.GetState(addr, key)}
+		if exist {
+			slot.Proof, err = db.GetStorageProof(addr, key)
+			if err != nil && proveErr == nil {
+				proveErr = fmt.Errorf("prove slot %v: %w", key, err)
+			}
+		}
+		p.Slots = append(p.Slots, slot)
+		return slot.Value
+	})
+	if proveErr != nil {
+		return nil, proveErr
+	}
+
+	return p, nil
+}
+
+func verify(root common.Hash, addr common.Address, loc common.Hash, p *StorageProof, val interface{}) (bool, error) {
+	if p.Address != addr {
+		return false, fmt.Errorf("%w: proof of %v, expect %v", ErrInvalidProof, p.Address, addr)
+	}
+
+	enc, err := verifyNode(root, crypto.Keccak256(addr.Bytes()), p.AccountProof)
+	if err != nil {
+		return false, fmt.Errorf("%w: account: %v", ErrInvalidProof, err)
+	}
+	storageRoot := types.EmptyRootHash
+	if len(enc) != 0 {
+		var account state.Account
+		if err := rlp.DecodeBytes(enc, &account); err != nil {
+			return false, fmt.Errorf("%w: account: %v", ErrInvalidProof, err)
+		}
+		storageRoot = account.Root
+	}
+
+	// the slots must be exactly the ones readValue asks for
+	next := 0
+	data, fixed, ok := readValue(loc, func(key common.Hash) common.Hash {
+		if err != nil {
+			return common.Hash{}
+		}
+		if next >= len(p.Slots) || p.Slots[next].Key != key {
+			err = fmt.Errorf("%w: missing slot %v", ErrInvalidProof, key)
+			return common.Hash{}
+		}
+		slot := p.Slots[next]
+		next++
+
+		enc, verr := verifyNode(storageRoot, crypto.Keccak256(key.Bytes()), slot.Proof)
+		if verr != nil {
+			err = fmt.Errorf("%w: slot %v: %v", ErrInvalidProof, key, verr)
+			return common.Hash{}
+		}
+		var value []byte
+		if len(enc) != 0 {
+			if value, _, verr = rlp.SplitString(enc); verr != nil {
+				err = fmt.Errorf("%w: slot %v: %v", ErrInvalidProof, key, verr)
+				return common.Hash{}
+			}
+		}
+		if common.BytesToHash(value) != slot.Value {
+			err = fmt.Errorf("%w: slot %v has value %x", ErrInvalidProof, key, value)
+			return common.Hash{}
+		}
+
+		return slot.Value
+	})
+	if err != nil {
+		return false, err
+	}
+	if next != len(p.Slots) {
+		return false, fmt.Errorf("%w: %d extra slots", ErrInvalidProof, len(p.Slots)-next)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	if reflect.ValueOf(val).Kind() != reflect.Ptr {
+		panic("val must be pointer")
+	}
+	if len(data) == 0 {
+		elem := reflect.ValueOf(val).Elem()
+		if !isSingleSlot(elem.Type()) {
+			return false, fmt.Errorf("%w: cannot decode fixed-size value into %v", ErrInvalidProof, elem.Type())
+		}
+		decodeSingle(fixed, elem)
+		return true, nil
+	}
+	if err := json.Unmarshal(data, val); err != nil {
+		return false, fmt.Errorf("%w: cannot decode value: %v", ErrInvalidProof, err)
+	}
+
+	return true, nil
+}
+
+// readValue reads the slot at loc itself, which holds fixed-size values.
+// If it is zero, it reads the data at loc like readData, which may also be
+// fixed bytes stored as JSON by earlier versions, and if there is none,
+// the assigned slot of a zero fixed-size value. It reports whether the
+// value is assigned.
+func readValue(loc common.Hash, getSlot func(key common.Hash) common.Hash) ([]byte, common.Hash, bool) {
+	if fixed := getSlot(loc); fixed != (common.Hash{}) {
+		return nil, fixed, true
+	}
+	if data := readData(loc, getSlot); len(data) != 0 {
+		return data, common.Hash{}, true
+	}
+
+	return nil, common.Hash{}, getSlot(assignedSlot(loc)) != (common.Hash{})
+}
+
+// verifyNode returns the value of key proven by proof,
+// which is empty if the key is proven absent.
+func verifyNode(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	if root == types.EmptyRootHash {
+		return nil, nil
+	}
+
+	db := memorydb.New()
+	for _, node := range proof {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+
+	return trie.VerifyProof(root, key, db)
+}
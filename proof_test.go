@@ -0,0 +1,114 @@
+package ethtypes
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageProof(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	st, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	tf, _ := NewTypeFactory(st, addr)
+
+	// longer than one chunk
+	long := "a value which does not fit into a single storage slot"
+	tf.NewString("greeting", long)
+	balances := tf.NewMap("balances", StringType, IntType)
+	balances.Set("alice", 42)
+
+	root, err := st.Commit(false)
+	assert.NoError(t, err)
+
+	proof, err := ProveVariable(st, addr, "greeting")
+	assert.NoError(t, err)
+	// the zero slot of fixed-size values, the length slot and 2 chunks
+	assert.Len(t, proof.Slots, 4)
+	var greeting string
+	ok, err := VerifyVariable(root, addr, "greeting", proof, &greeting)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, long, greeting)
+
+	// the proof does not prove another variable or root
+	_, err = VerifyVariable(root, addr, "other", proof, &greeting)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+	_, err = VerifyVariable(common.Hash{1}, addr, "greeting", proof, &greeting)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	proof.Slots[1].Value[0] ^= 1
+	_, err = VerifyVariable(root, addr, "greeting", proof, &greeting)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	proof, err = ProveMapEntry(st, addr, "balances", "alice")
+	assert.NoError(t, err)
+	var balance int
+	ok, err = VerifyMapEntry(root, addr, "balances", "alice", proof, &balance)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 42, balance)
+
+	// absent entries are proven as well
+	proof, err = ProveMapEntry(st, addr, "balances", "bob")
+	assert.NoError(t, err)
+	ok, err = VerifyMapEntry(root, addr, "balances", "bob", proof, &balance)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	proof, err = ProveVariable(st, common.HexToAddress("456"), "greeting")
+	assert.NoError(t, err)
+	ok, err = VerifyVariable(root, common.HexToAddress("456"), "greeting", proof, &greeting)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFixedBytesProof(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	st, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	tf, _ := NewTypeFactory(st, addr)
+
+	h := common.HexToHash("0x0102")
+	tf.NewVariable("root", h)
+	root, err := st.Commit(false)
+	assert.NoError(t, err)
+
+	// only the slot of the value
+	proof, err := ProveVariable(st, addr, "root")
+	assert.NoError(t, err)
+	assert.Len(t, proof.Slots, 1)
+	var got common.Hash
+	ok, err := VerifyVariable(root, addr, "root", proof, &got)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, h, got)
+
+	// a write hides the JSON of earlier versions
+	old := tf.GetVariable("old", HashType)
+	tf.state.Write(old.Addr(), []byte(`"0x0000000000000000000000000000000000000000000000000000000000000506"`))
+	old.Set(h)
+	root, _ = st.Commit(false)
+	proof, err = ProveVariable(st, addr, "old")
+	assert.NoError(t, err)
+	got = common.Hash{}
+	ok, err = VerifyVariable(root, addr, "old", proof, &got)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, h, got)
+
+	// a zero value is proven by its assigned slot
+	tf.NewVariable("zero", common.Hash{})
+	root, _ = st.Commit(false)
+	proof, err = ProveVariable(st, addr, "zero")
+	assert.NoError(t, err)
+	assert.Len(t, proof.Slots, 3)
+	got = h
+	ok, err = VerifyVariable(root, addr, "zero", proof, &got)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, common.Hash{}, got)
+}
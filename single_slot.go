@@ -0,0 +1,94 @@
+package ethtypes
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// isSingleSlot reports whether values of typ are stored in a single slot
+// without a length header. A zero value also sets the assigned slot, so it
+// can be told apart from a value which is not assigned.
+func isSingleSlot(typ reflect.Type) bool {
+	return typ == DecimalType || typ == EnumType || isFixedBytes(typ)
+}
+
+// encodeSingle returns the slot holding v, which may be zero.
+func encodeSingle(typ reflect.Type, v reflect.Value) []byte {
+	var slot common.Hash
+	if typ == DecimalType {
+		if v.Type() != typ {
+			panic(fmt.Sprintf("expect type: %v, actual type: %v", typ, v.Type()))
+		}
+		slot = v.Interface().(Decimal).slot()
+	} else if typ == EnumType {
+		binary.BigEndian.PutUint16(slot[30:], uint16(v.Interface().(EnumValue).index)+1)
+	} else {
+		slot = encodeFixed(typ, v)
+	}
+
+	return slot.Bytes()
+}
+
+// decodeSingle decodes slot into elem.
+func decodeSingle(slot common.Hash, elem reflect.Value) {
+	if elem.Type() == DecimalType {
+		elem.Set(reflect.ValueOf(decimalFromSlot(slot)))
+		return
+	}
+	if elem.Type() == EnumType {
+		elem.Set(reflect.ValueOf(EnumValue{index: enumIndex(slot)}))
+		return
+	}
+
+	decodeFixed(slot, elem)
+}
+
+// enumIndex returns the index of the member held by slot, which stores
+// the index plus one so that the first member is not the zero slot.
+func enumIndex(slot common.Hash) uint8 {
+	n := binary.BigEndian.Uint16(slot[30:])
+	if n == 0 {
+		return 0
+	}
+
+	return uint8(n - 1)
+}
+
+// isFixedBytes reports whether typ is common.Hash or one of the unnamed
+// byte arrays of Bytes1Type to Bytes32Type. Named arrays such as
+// common.Address are stored as JSON like any other type.
+func isFixedBytes(typ reflect.Type) bool {
+	if typ == HashType {
+		return true
+	}
+
+	return typ.Kind() == reflect.Array && typ.Name() == "" &&
+		typ.Elem().Kind() == reflect.Uint8 && typ.Len() <= 32
+}
+
+// encodeFixed returns the slot holding v left aligned, like a
+// Solidity bytesN. v must be convertible to typ.
+func encodeFixed(typ reflect.Type, v reflect.Value) common.Hash {
+	if v.Type() != typ {
+		if !v.Type().ConvertibleTo(typ) {
+			panic(fmt.Sprintf("expect type: %v, actual type: %v", typ, v.Type()))
+		}
+		v = v.Convert(typ)
+	}
+
+	var slot common.Hash
+	reflect.Copy(reflect.ValueOf(slot[:]), v)
+	return slot
+}
+
+// decodeFixed copies the leading bytes of slot into the byte array elem.
+func decodeFixed(slot common.Hash, elem reflect.Value) {
+	if elem.Kind() != reflect.Array || elem.Type().Elem().Kind() != reflect.Uint8 {
+		panic(fmt.Sprintf("cannot decode %d bytes into %v", common.HashLength, elem.Type()))
+	}
+
+	reflect.Copy(elem, reflect.ValueOf(slot[:]))
+}
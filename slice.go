@@ -1,13 +1,17 @@
 package ethtypes
 
 import (
-	"fmt"
-	"math"
 	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
+// BasicSlice stores its elements in a BasicArray of the same name,
+// whose length is the capacity of the slice. Elements are addressed by
+// index under the array's prefix, so growing the capacity only rewrites
+// that length and never moves any element.
 type BasicSlice struct {
-	arr   Array
+	arr   *BasicArray
 	name  string
 	state *ContractState
 	len   StateVariable
@@ -104,51 +108,164 @@ func (s *BasicSlice) Del(index int) {
 	if s.isOutOfRange(index) {
 		panic(ErrIndexOutOfRange)
 	}
-	s.arr.Del(index)
-	s.len.Set(s.Len() - 1)
+
+	length := s.Len()
+	s.arr.move(s.arr, index, index+1, length)
+	s.arr.getElem(length - 1).Del()
+	s.len.Set(length - 1)
+}
+
+func (s *BasicSlice) CopyFrom(src Array, dstFrom, srcFrom, srcTo int) error {
+	return s.arr.copyFrom(src, dstFrom, srcFrom, srcTo, s.Len())
+}
+
+func (s *BasicSlice) GetRange(from, to int, vals interface{}) {
+	checkRange(from, to, s.Len())
+	s.arr.getRange(from, to, vals)
+}
+
+func (s *BasicSlice) SetRange(from int, vals interface{}) {
+	s.arr.setRange(from, vals, s.Len())
 }
 
-func (s *BasicSlice) CopyFrom(src Array, dstFrom, srcFrom, srcTo int) {
-	count := srcTo - srcFrom
-	if count > s.Len()-dstFrom {
+func (s *BasicSlice) Swap(i, j int) {
+	if length := s.Len(); i < 0 || i >= length || j < 0 || j >= length {
 		panic(ErrIndexOutOfRange)
 	}
 
-	s.arr.CopyFrom(src, dstFrom, srcFrom, srcTo)
+	s.arr.swap(i, j)
 }
 
-func (s *BasicSlice) Append(vals ...interface{}) {
-	length := s.Len()
+func (s *BasicSlice) Reverse() {
+	s.arr.reverse(0, s.Len())
+}
 
-	if cap := s.Cap(); cap <= len(vals)+length {
-		// extend cap as max (2 * old cap, the length of elements waited appending)
-		// TODO: add max function in utils package in future,
-		// and instead of math.Max()
-		newCap := int(math.Max(float64(2*cap), float64(len(vals))))
-		newArray, err := NewBasicArray(s.state, s.name, newCap, s.ElemType())
-		if err != nil {
-			panic(fmt.Sprintf("extend slice cap err: %s", err))
+func (s *BasicSlice) Fill(val interface{}) {
+	s.arr.fill(val, 0, s.Len())
+}
+
+// grow makes the capacity at least want.
+func (s *BasicSlice) grow(want int) {
+	if cap := s.Cap(); cap < want {
+		// extend cap as max (2 * old cap, want)
+		newCap := 2 * cap
+		if newCap < want {
+			newCap = want
 		}
-		newArray.CopyFrom(s.arr, 0, 0, s.arr.Len())
-		// TODO: delete old array
-		s.arr = newArray
+		s.arr.setLen(newCap)
 	}
+}
+
+func (s *BasicSlice) Append(vals ...interface{}) {
+	length := s.Len()
+	s.grow(length + len(vals))
 
 	for _, v := range vals {
+		s.arr.getElem(length).Set(v)
 		length++
-		index := length - 1
-		s.arr.Set(index, v)
 	}
 
 	s.len.Set(length)
 }
 
+// Insert moves the elements after index backward
+// starting from the last one, so none is overwritten.
+func (s *BasicSlice) Insert(index int, vals ...interface{}) {
+	length := s.Len()
+	if index < 0 || index > length {
+		panic(ErrIndexOutOfRange)
+	}
+	s.grow(length + len(vals))
+
+	for i := length - 1; i >= index; i-- {
+		s.arr.getElem(i + len(vals)).setRaw(s.arr.getElem(i).raw())
+	}
+	for i, v := range vals {
+		s.arr.getElem(index + i).Set(v)
+	}
+
+	s.len.Set(length + len(vals))
+}
+
 func (s *BasicSlice) Pop(val interface{}) {
-	s.Get(s.Len()-1, val)
-	// s.setRaw(s.Len()-1, nil)
-	s.len.Set(s.Len() - 1)
+	length := s.Len()
+	s.Get(length-1, val)
+	s.arr.getElem(length - 1).Del()
+	s.len.Set(length - 1)
+}
+
+func (s *BasicSlice) Truncate(n int) {
+	length := s.Len()
+	if n < 0 || n > length {
+		panic(ErrIndexOutOfRange)
+	}
+
+	for i := n; i < length; i++ {
+		s.arr.getElem(i).Del()
+	}
+	s.len.Set(n)
+}
+
+func (s *BasicSlice) Clear() {
+	s.Truncate(0)
+}
+
+// ShrinkToFit also deletes elements left beyond the length
+// by versions which did not delete popped elements.
+func (s *BasicSlice) ShrinkToFit() {
+	length, cap := s.Len(), s.Cap()
+	for i := length; i < cap; i++ {
+		if elem := s.arr.getElem(i); elem.IsAssigned() {
+			elem.Del()
+		}
+	}
+	s.arr.setLen(length)
 }
 
 func (s *BasicSlice) isOutOfRange(index int) bool {
 	return s.Len() <= index || index < 0
 }
+
+func (s *BasicSlice) Sort(less Less) {
+	SortArray(s, less)
+}
+
+func (s *BasicSlice) IsSorted(less Less) bool {
+	return IsArraySorted(s, less)
+}
+
+func (s *BasicSlice) SearchSorted(val interface{}, less Less) int {
+	return SearchArray(s, val, less)
+}
+
+func (s *BasicSlice) InsertSorted(val interface{}, less Less) int {
+	return InsertSorted(s, val, less)
+}
+
+func (s *BasicSlice) RemoveSorted(val interface{}, less Less) bool {
+	return RemoveSorted(s, val, less)
+}
+
+func (s *BasicSlice) Range(fn func(index int, val interface{}) bool) {
+	RangeArray(s, fn)
+}
+
+func (s *BasicSlice) Iter() Iterator {
+	return IterArray(s, 0, -1, false)
+}
+
+func (s *BasicSlice) ReverseIter() Iterator {
+	return IterArray(s, 0, -1, true)
+}
+
+func (s *BasicSlice) Page(offset, limit int) Iterator {
+	return IterArray(s, offset, limit, false)
+}
+
+func (s *BasicSlice) Root() common.Hash {
+	return s.arr.Root()
+}
+
+func (s *BasicSlice) Proof(index int) (*MerkleProof, error) {
+	return s.arr.proof(index, s.Len())
+}
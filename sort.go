@@ -0,0 +1,184 @@
+package ethtypes
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+)
+
+// Less reports whether the element a must sort before b.
+// a and b are element values like the ones GetArrayElems returns.
+// A nil Less sorts by NaturalLess.
+type Less func(a, b interface{}) bool
+
+// NaturalLess orders numbers and big.Int by value, bools as false
+// before true, and strings, bytes and fixed-size byte arrays such as
+// common.Address lexicographically. It panics for other types.
+func NaturalLess(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		panic(fmt.Sprintf("cannot compare %v with %v", va.Type(), vb.Type()))
+	}
+
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return va.Int() < vb.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return va.Uint() < vb.Uint()
+	case reflect.Float32, reflect.Float64:
+		return va.Float() < vb.Float()
+	case reflect.String:
+		return va.String() < vb.String()
+	case reflect.Bool:
+		return !va.Bool() && vb.Bool()
+	case reflect.Slice, reflect.Array:
+		if va.Type().Elem().Kind() == reflect.Uint8 {
+			return bytes.Compare(toBytes(va), toBytes(vb)) < 0
+		}
+	case reflect.Struct:
+		if va.Type() == BigIntType {
+			x, y := a.(big.Int), b.(big.Int)
+			return x.Cmp(&y) < 0
+		}
+		if va.Type() == DecimalType {
+			return a.(Decimal).Cmp(b.(Decimal)) < 0
+		}
+	}
+
+	panic(fmt.Sprintf("type %v has no natural order", va.Type()))
+}
+
+func toBytes(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// loadElems returns pointers to all elements of arr.
+func loadElems(arr Array) []interface{} {
+	elems := make([]interface{}, arr.Len())
+	for i := range elems {
+		elems[i] = reflect.New(arr.ElemType()).Interface()
+		arr.Get(i, elems[i])
+	}
+
+	return elems
+}
+
+func (less Less) ptrs(a, b interface{}) bool {
+	if less == nil {
+		less = NaturalLess
+	}
+
+	return less(reflect.ValueOf(a).Elem().Interface(), reflect.ValueOf(b).Elem().Interface())
+}
+
+// SortArray sorts arr in storage. It reads every element once,
+// sorts them in memory and only writes the elements that moved.
+// The sort is stable.
+func SortArray(arr Array, less Less) {
+	elems := loadElems(arr)
+	sorted := make([]interface{}, len(elems))
+	copy(sorted, elems)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less.ptrs(sorted[i], sorted[j])
+	})
+
+	for i := range sorted {
+		if !reflect.DeepEqual(sorted[i], elems[i]) {
+			arr.Set(i, sorted[i])
+		}
+	}
+}
+
+// IsArraySorted reports whether arr is sorted by less.
+func IsArraySorted(arr Array, less Less) bool {
+	length := arr.Len()
+	if length < 2 {
+		return true
+	}
+
+	prev := reflect.New(arr.ElemType()).Interface()
+	arr.Get(0, prev)
+	for i := 1; i < length; i++ {
+		cur := reflect.New(arr.ElemType()).Interface()
+		arr.Get(i, cur)
+		if less.ptrs(cur, prev) {
+			return false
+		}
+		prev = cur
+	}
+
+	return true
+}
+
+// SearchArray binary searches the sorted arr for val, and returns
+// the smallest index whose element is not less than val, which is
+// arr.Len() if there is none. It reads O(log n) elements.
+func SearchArray(arr Array, val interface{}, less Less) int {
+	if less == nil {
+		less = NaturalLess
+	}
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	return sort.Search(arr.Len(), func(i int) bool {
+		elem := reflect.New(arr.ElemType())
+		arr.Get(i, elem.Interface())
+		return !less(elem.Elem().Interface(), v.Interface())
+	})
+}
+
+// InsertSorted inserts val into the sorted s after
+// the elements not greater than it, and returns its index.
+func InsertSorted(s Slice, val interface{}, less Less) int {
+	if less == nil {
+		less = NaturalLess
+	}
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	index := sort.Search(s.Len(), func(i int) bool {
+		elem := reflect.New(s.ElemType())
+		s.Get(i, elem.Interface())
+		return less(v.Interface(), elem.Elem().Interface())
+	})
+	s.Insert(index, val)
+
+	return index
+}
+
+// RemoveSorted removes the first element equal to val from the
+// sorted s, and reports whether there was one.
+func RemoveSorted(s Slice, val interface{}, less Less) bool {
+	index := SearchArray(s, val, less)
+	if index == s.Len() {
+		return false
+	}
+
+	if less == nil {
+		less = NaturalLess
+	}
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	elem := reflect.New(s.ElemType())
+	s.Get(index, elem.Interface())
+	if less(v.Interface(), elem.Elem().Interface()) {
+		return false
+	}
+
+	s.Del(index)
+	return true
+}
@@ -12,6 +12,12 @@ import (
 type ContractState struct {
 	db   vm.StateDB
 	addr common.Address
+	// readOnly makes every write panic with ErrReadOnly
+	readOnly bool
+	// strict checks full types instead of kinds, see WithStrictTypes
+	strict bool
+	// ns is the namespace of all names, zero for the root namespace
+	ns common.Hash
 }
 
 // TODO: To avoid same variable's name
@@ -24,46 +30,103 @@ func NewContractState(db vm.StateDB, addr common.Address) *ContractState {
 }
 
 const (
-	lengthSuffix = "length"
-	indexSuffix  = "index"
+	lengthSuffix    = "length"
+	indexSuffix     = "index"
+	assignedSuffix  = "assigned"
+	namespacePrefix = "namespace_"
 )
 
+// nameHash returns the location of the data called name in namespace ns.
+func nameHash(ns common.Hash, name string) common.Hash {
+	if ns == (common.Hash{}) {
+		return sha256.Sum256([]byte(name))
+	}
+
+	return sha256.Sum256(append(append([]byte(nil), ns[:]...), name...))
+}
+
+// hash returns the location of the data called name.
+func (s *ContractState) hash(name string) common.Hash {
+	return nameHash(s.ns, name)
+}
+
+// namespace returns a ContractState of the same contract,
+// whose names are hashed under the child namespace called name.
+func (s *ContractState) namespace(name string) *ContractState {
+	child := *s
+	child.ns = s.hash(namespacePrefix + name)
+	return &child
+}
+
+// lengthSlot returns the slot holding the length of the data at hash.
+func lengthSlot(hash common.Hash) common.Hash {
+	return sha256.Sum256(append(append([]byte(nil), hash[:]...), lengthSuffix...))
+}
+
+// assignedSlot returns the slot marking the single-slot value at hash as
+// assigned while the value itself is the zero slot.
+func assignedSlot(hash common.Hash) common.Hash {
+	return sha256.Sum256(append(append([]byte(nil), hash[:]...), assignedSuffix...))
+}
+
+// chunkSlot returns the slot holding the i-th 32 bytes of the data at hash.
+func chunkSlot(hash common.Hash, i int) common.Hash {
+	return sha256.Sum256(append(append([]byte(nil), hash.Bytes()...),
+		[]byte(fmt.Sprintf("%d_%s", i, indexSuffix))...))
+}
+
 //Exists - check data has been saved or not
 func (s *ContractState) Exists(hash common.Hash) bool {
-	lhash := common.Hash(sha256.Sum256(append(append([]byte(nil), hash[:]...), lengthSuffix...)))
-	state := s.db.GetState(s.addr, lhash)
+	state := s.db.GetState(s.addr, lengthSlot(hash))
 
 	return state != common.Hash{}
 }
 
+// Delete clears the length and every chunk of the data at hash.
 func (s *ContractState) Delete(hash common.Hash) {
-	lhash := common.Hash(sha256.Sum256(append(append([]byte(nil), hash[:]...), lengthSuffix...)))
-	s.db.SetState(s.addr, lhash, common.Hash{})
+	lhash := lengthSlot(hash)
+	length := int(s.db.GetState(s.addr, lhash).Big().Int64())
+	for offset := 0; offset < length; offset += 32 {
+		s.setSlot(chunkSlot(hash, offset/32), common.Hash{})
+	}
+	s.setSlot(lhash, common.Hash{})
+}
+
+// getSlot and setSlot access a single slot directly,
+// for values which always take 32 bytes.
+func (s *ContractState) getSlot(key common.Hash) common.Hash {
+	return s.db.GetState(s.addr, key)
+}
+
+func (s *ContractState) setSlot(key, val common.Hash) {
+	if s.readOnly {
+		panic(ErrReadOnly)
+	}
+	s.db.SetState(s.addr, key, val)
 }
 
 func (s *ContractState) Write(hash common.Hash, data []byte) {
-	lhash := common.Hash(sha256.Sum256(append(append([]byte(nil), hash[:]...), lengthSuffix...)))
 	length := len(data)
 	for offset := 0; offset < length; offset += 32 {
-		ihash := common.Hash(sha256.Sum256(append(append([]byte(nil), hash.Bytes()...),
-			[]byte(fmt.Sprintf("%d_%s", offset/32, indexSuffix))...)))
 		end := offset + 32
 		if end > length {
 			end = length
 		}
-		s.db.SetState(s.addr, ihash, common.BytesToHash(data[offset:end]))
+		s.setSlot(chunkSlot(hash, offset/32), common.BytesToHash(data[offset:end]))
 	}
-	s.db.SetState(s.addr, lhash, common.BigToHash(big.NewInt(int64(length))))
+	s.setSlot(lengthSlot(hash), common.BigToHash(big.NewInt(int64(length))))
 }
 
 func (s *ContractState) Read(hash common.Hash) []byte {
-	lhash := common.Hash(sha256.Sum256(append(append([]byte(nil), hash[:]...), lengthSuffix...)))
-	length := int(s.db.GetState(s.addr, lhash).Big().Int64())
+	return readData(hash, s.getSlot)
+}
+
+// readData reassembles the data at hash from the slots returned by getSlot.
+func readData(hash common.Hash, getSlot func(key common.Hash) common.Hash) []byte {
+	length := int(getSlot(lengthSlot(hash)).Big().Int64())
 	data := make([]byte, length)
 	for offset := 0; offset < length; offset += 32 {
-		ihash := common.Hash(sha256.Sum256(append(append([]byte(nil), hash.Bytes()...),
-			[]byte(fmt.Sprintf("%d_%s", offset/32, indexSuffix))...)))
-		val := s.db.GetState(s.addr, ihash)
+		val := getSlot(chunkSlot(hash, offset/32))
 		end := offset + 32
 		if end > length {
 			end = length
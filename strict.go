@@ -0,0 +1,125 @@
+package ethtypes
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// TypeError is the panic value of a failed type check in strict mode.
+type TypeError struct {
+	// Op is what was done, such as "set", "get" or "key"
+	Op string
+	// Name is the name of the variable or element
+	Name     string
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%s %s: expect type %v, actual type %v", e.Op, e.Name, e.Expected, e.Actual)
+}
+
+func (e *TypeError) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+// Widens reports whether every value of type from is also a value of
+// type to, which is how strict mode converts numbers:
+//
+//   - a signed or unsigned integer to one of the same signedness and
+//     at least the same size, int and uint have strconv.IntSize bits
+//   - an unsigned integer to a larger signed integer
+//   - float32 to float64
+//
+// Any type widens to itself.
+func Widens(from, to reflect.Type) bool {
+	if from == to {
+		return true
+	}
+
+	switch {
+	case isInt(from) && isInt(to), isUint(from) && isUint(to):
+		return bits(from) <= bits(to)
+	case isUint(from) && isInt(to):
+		return bits(from) < bits(to)
+	case from.Kind() == reflect.Float32 && to.Kind() == reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func isInt(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func bits(t reflect.Type) int {
+	if k := t.Kind(); k == reflect.Int || k == reflect.Uint {
+		return strconv.IntSize
+	}
+	return t.Bits()
+}
+
+// checkValue returns v as a value of typ, or panics with
+// a *TypeError if v does not widen to typ.
+func checkValue(op, name string, typ reflect.Type, v reflect.Value) reflect.Value {
+	if !Widens(v.Type(), typ) {
+		panic(&TypeError{Op: op, Name: name, Expected: typ, Actual: v.Type()})
+	}
+	if v.Type() != typ {
+		v = v.Convert(typ)
+	}
+
+	return v
+}
+
+// checkFloats panics with ErrFloatType if t rejects floats
+// and one of types holds them.
+func (t *TypeFactory) checkFloats(name string, types ...reflect.Type) {
+	if !t.noFloats {
+		return
+	}
+
+	for _, typ := range types {
+		if typ != nil && hasFloat(typ, map[reflect.Type]bool{}) {
+			panic(fmt.Errorf("%w: %s holds %v", ErrFloatType, name, typ))
+		}
+	}
+}
+
+func hasFloat(typ reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasFloat(typ.Elem(), seen)
+	case reflect.Map:
+		return hasFloat(typ.Key(), seen) || hasFloat(typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if hasFloat(typ.Field(i).Type, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
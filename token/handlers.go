@@ -0,0 +1,66 @@
+package token
+
+import (
+	"math/big"
+
+	"github.com/TheStarBoys/ethtypes/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC20ABI is the ABI of the ERC-20 standard, served by Handlers.
+const ERC20ABI = `[
+	{"type": "function", "name": "name", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "string"}]},
+	{"type": "function", "name": "symbol", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "string"}]},
+	{"type": "function", "name": "decimals", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "uint8"}]},
+	{"type": "function", "name": "totalSupply", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "uint256"}]},
+	{"type": "function", "name": "balanceOf", "stateMutability": "view", "inputs": [{"name": "account", "type": "address"}], "outputs": [{"name": "", "type": "uint256"}]},
+	{"type": "function", "name": "allowance", "stateMutability": "view", "inputs": [{"name": "owner", "type": "address"}, {"name": "spender", "type": "address"}], "outputs": [{"name": "", "type": "uint256"}]},
+	{"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"name": "", "type": "bool"}]},
+	{"type": "function", "name": "approve", "inputs": [{"name": "spender", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"name": "", "type": "bool"}]},
+	{"type": "function", "name": "transferFrom", "inputs": [{"name": "from", "type": "address"}, {"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"name": "", "type": "bool"}]},
+	{"type": "event", "name": "Transfer", "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "value", "type": "uint256", "indexed": false}]},
+	{"type": "event", "name": "Approval", "inputs": [{"name": "owner", "type": "address", "indexed": true}, {"name": "spender", "type": "address", "indexed": true}, {"name": "value", "type": "uint256", "indexed": false}]}
+]`
+
+// Handlers returns the handlers of the methods of ERC20ABI over the
+// ledger created by New in the namespace called namespace of the
+// TypeFactory of the contract, or in the TypeFactory itself if namespace
+// is empty. Minting and burning are left to the handlers of the contract.
+func Handlers(namespace string) map[string]interface{} {
+	get := func(ctx *precompile.Context) *Token {
+		if namespace == "" {
+			return Get(ctx.Factory)
+		}
+		return Get(ctx.Factory.Namespace(namespace))
+	}
+
+	return map[string]interface{}{
+		"name": func(ctx *precompile.Context) (string, error) {
+			return get(ctx).Name(), nil
+		},
+		"symbol": func(ctx *precompile.Context) (string, error) {
+			return get(ctx).Symbol(), nil
+		},
+		"decimals": func(ctx *precompile.Context) (uint8, error) {
+			return get(ctx).Decimals(), nil
+		},
+		"totalSupply": func(ctx *precompile.Context) (*big.Int, error) {
+			return get(ctx).TotalSupply(), nil
+		},
+		"balanceOf": func(ctx *precompile.Context, account common.Address) (*big.Int, error) {
+			return get(ctx).BalanceOf(account), nil
+		},
+		"allowance": func(ctx *precompile.Context, owner, spender common.Address) (*big.Int, error) {
+			return get(ctx).Allowance(owner, spender), nil
+		},
+		"transfer": func(ctx *precompile.Context, to common.Address, amount *big.Int) (bool, error) {
+			return true, get(ctx).Transfer(ctx.Caller, to, amount)
+		},
+		"approve": func(ctx *precompile.Context, spender common.Address, amount *big.Int) (bool, error) {
+			return true, get(ctx).Approve(ctx.Caller, spender, amount)
+		},
+		"transferFrom": func(ctx *precompile.Context, from, to common.Address, amount *big.Int) (bool, error) {
+			return true, get(ctx).TransferFrom(ctx.Caller, from, to, amount)
+		},
+	}
+}
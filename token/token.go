@@ -0,0 +1,245 @@
+// Package token keeps an ERC-20 ledger in the state of a contract:
+// balances, allowances and the total supply, with the semantics and
+// events of the ERC-20 standard.
+//
+// Handlers adapts a Token to the precompile package, so a precompiled
+// contract with the ABI of ERC20ABI behaves like a Solidity ERC-20:
+//
+//	c, err := precompile.New(token.ERC20ABI, addr, token.Handlers(""))
+//
+// Use TypeFactory.Namespace to keep the names of the ledger apart
+// from other state of the contract, and pass the namespace to Handlers.
+package token
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrZeroAddress           = errors.New("zero address")
+	ErrNegativeAmount        = errors.New("negative amount")
+	ErrInsufficientBalance   = errors.New("amount exceeds balance")
+	ErrInsufficientAllowance = errors.New("insufficient allowance")
+)
+
+var (
+	// TransferTopic is the topic of Transfer(address,address,uint256)
+	TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	// ApprovalTopic is the topic of Approval(address,address,uint256)
+	ApprovalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+)
+
+// Names of the state of a Token.
+const (
+	nameName        = "name"
+	symbolName      = "symbol"
+	decimalsName    = "decimals"
+	totalSupplyName = "totalSupply"
+	balancesName    = "balances"
+	allowancesName  = "allowances"
+)
+
+// allowanceKey is the key of the allowance of Spender over the tokens of Owner.
+type allowanceKey struct {
+	Owner   common.Address
+	Spender common.Address
+}
+
+var allowanceKeyType = reflect.TypeOf(allowanceKey{})
+
+func init() {
+	ethtypes.RegisterType(allowanceKeyType)
+}
+
+// Token is an ERC-20 ledger in the state of a TypeFactory.
+// Amounts are uint256, methods return an error instead of
+// changing anything if a call would fail in Solidity.
+type Token struct {
+	tf *ethtypes.TypeFactory
+	// opened on first use, since opening reads the descriptor
+	totalSupply ethtypes.Counter
+	balances    ethtypes.MapCounter
+	allowances  ethtypes.MapCounter
+}
+
+// New creates the ledger of the token in tf, without any supply.
+func New(tf *ethtypes.TypeFactory, name, symbol string, decimals uint8) *Token {
+	tf.NewString(nameName, name)
+	tf.NewString(symbolName, symbol)
+	tf.NewVariable(decimalsName, decimals)
+
+	return &Token{
+		tf:          tf,
+		totalSupply: tf.NewCounter(totalSupplyName, new(big.Int)),
+		balances:    tf.NewMapCounter(balancesName, ethtypes.AddressType),
+		allowances:  tf.NewMapCounter(allowancesName, allowanceKeyType),
+	}
+}
+
+// Get opens the ledger created by New in tf. Each part of the
+// ledger is only opened when a method needs it.
+func Get(tf *ethtypes.TypeFactory) *Token {
+	return &Token{tf: tf}
+}
+
+func (t *Token) supply() ethtypes.Counter {
+	if t.totalSupply == nil {
+		t.totalSupply = t.tf.GetCounter(totalSupplyName)
+	}
+	return t.totalSupply
+}
+
+func (t *Token) balanceMap() ethtypes.MapCounter {
+	if t.balances == nil {
+		t.balances = t.tf.GetMapCounter(balancesName, ethtypes.AddressType)
+	}
+	return t.balances
+}
+
+func (t *Token) allowanceMap() ethtypes.MapCounter {
+	if t.allowances == nil {
+		t.allowances = t.tf.GetMapCounter(allowancesName, allowanceKeyType)
+	}
+	return t.allowances
+}
+
+func (t *Token) Name() string {
+	var name string
+	t.tf.GetVariable(nameName, ethtypes.StringType).Get(&name)
+	return name
+}
+
+func (t *Token) Symbol() string {
+	var symbol string
+	t.tf.GetVariable(symbolName, ethtypes.StringType).Get(&symbol)
+	return symbol
+}
+
+func (t *Token) Decimals() uint8 {
+	var decimals uint8
+	t.tf.GetVariable(decimalsName, ethtypes.Uint8Type).Get(&decimals)
+	return decimals
+}
+
+func (t *Token) TotalSupply() *big.Int {
+	return t.supply().Get()
+}
+
+func (t *Token) BalanceOf(owner common.Address) *big.Int {
+	return t.balanceMap().Get(owner)
+}
+
+func (t *Token) Allowance(owner, spender common.Address) *big.Int {
+	return t.allowanceMap().Get(allowanceKey{Owner: owner, Spender: spender})
+}
+
+// Transfer moves amount from from to to and emits Transfer.
+func (t *Token) Transfer(from, to common.Address, amount *big.Int) error {
+	if err := checkAmount(amount, from, to); err != nil {
+		return err
+	}
+	if t.balanceMap().Get(from).Cmp(amount) < 0 {
+		return ErrInsufficientBalance
+	}
+
+	t.balanceMap().Sub(from, amount)
+	t.balanceMap().Add(to, amount)
+	t.emit(TransferTopic, from, to, amount)
+
+	return nil
+}
+
+// Approve sets the allowance of spender over the tokens
+// of owner to amount and emits Approval.
+func (t *Token) Approve(owner, spender common.Address, amount *big.Int) error {
+	if err := checkAmount(amount, owner, spender); err != nil {
+		return err
+	}
+
+	t.allowanceMap().Set(allowanceKey{Owner: owner, Spender: spender}, amount)
+	t.emit(ApprovalTopic, owner, spender, amount)
+
+	return nil
+}
+
+// TransferFrom moves amount from from to to on behalf of spender,
+// spending its allowance. An allowance of 2^256-1 is never spent.
+func (t *Token) TransferFrom(spender, from, to common.Address, amount *big.Int) error {
+	if err := checkAmount(amount, spender); err != nil {
+		return err
+	}
+
+	key := allowanceKey{Owner: from, Spender: spender}
+	allowance := t.allowanceMap().Get(key)
+	if allowance.Cmp(amount) < 0 {
+		return ErrInsufficientAllowance
+	}
+	if err := t.Transfer(from, to, amount); err != nil {
+		return err
+	}
+	if allowance.Cmp(math.MaxBig256) != 0 {
+		t.allowanceMap().Sub(key, amount)
+	}
+
+	return nil
+}
+
+// Mint creates amount for to and emits Transfer from the zero
+// address. It returns ethtypes.ErrOverflow if the total supply
+// would not fit into 256 bits.
+func (t *Token) Mint(to common.Address, amount *big.Int) error {
+	if err := checkAmount(amount, to); err != nil {
+		return err
+	}
+	supply := new(big.Int).Add(t.supply().Get(), amount)
+	if supply.Cmp(math.MaxBig256) > 0 {
+		return ethtypes.ErrOverflow
+	}
+
+	// balances never exceed the total supply
+	t.supply().Set(supply)
+	t.balanceMap().Add(to, amount)
+	t.emit(TransferTopic, common.Address{}, to, amount)
+
+	return nil
+}
+
+// Burn destroys amount of from and emits Transfer to the zero address.
+func (t *Token) Burn(from common.Address, amount *big.Int) error {
+	if err := checkAmount(amount, from); err != nil {
+		return err
+	}
+	if t.balanceMap().Get(from).Cmp(amount) < 0 {
+		return ErrInsufficientBalance
+	}
+
+	t.balanceMap().Sub(from, amount)
+	t.supply().Sub(amount)
+	t.emit(TransferTopic, from, common.Address{}, amount)
+
+	return nil
+}
+
+func (t *Token) emit(topic common.Hash, from, to common.Address, amount *big.Int) {
+	t.tf.AddLog([]common.Hash{topic, from.Hash(), to.Hash()}, common.BigToHash(amount).Bytes())
+}
+
+func checkAmount(amount *big.Int, accounts ...common.Address) error {
+	if amount.Sign() < 0 {
+		return ErrNegativeAmount
+	}
+	for _, account := range accounts {
+		if account == (common.Address{}) {
+			return ErrZeroAddress
+		}
+	}
+
+	return nil
+}
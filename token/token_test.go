@@ -0,0 +1,109 @@
+package token
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/TheStarBoys/ethtypes"
+	"github.com/TheStarBoys/ethtypes/precompile"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	alice = common.HexToAddress("0xa1")
+	bob   = common.HexToAddress("0xb0")
+	addr  = common.HexToAddress("0x100")
+)
+
+func TestToken(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+
+	tok := New(tf.Namespace("token"), "Gold", "GLD", 18)
+	assert.NoError(t, tok.Mint(alice, big.NewInt(100)))
+	assert.Equal(t, big.NewInt(100), tok.TotalSupply())
+
+	assert.NoError(t, tok.Transfer(alice, bob, big.NewInt(30)))
+	assert.Equal(t, big.NewInt(70), tok.BalanceOf(alice))
+	assert.Equal(t, big.NewInt(30), tok.BalanceOf(bob))
+	assert.ErrorIs(t, tok.Transfer(bob, alice, big.NewInt(31)), ErrInsufficientBalance)
+	assert.ErrorIs(t, tok.Transfer(bob, common.Address{}, big.NewInt(1)), ErrZeroAddress)
+	assert.ErrorIs(t, tok.Transfer(bob, alice, big.NewInt(-1)), ErrNegativeAmount)
+
+	assert.NoError(t, tok.Approve(alice, bob, big.NewInt(50)))
+	assert.NoError(t, tok.TransferFrom(bob, alice, bob, big.NewInt(20)))
+	assert.Equal(t, big.NewInt(30), tok.Allowance(alice, bob))
+	assert.ErrorIs(t, tok.TransferFrom(bob, alice, bob, big.NewInt(31)), ErrInsufficientAllowance)
+	// a failed transfer spends nothing
+	assert.ErrorIs(t, tok.TransferFrom(bob, alice, common.Address{}, big.NewInt(1)), ErrZeroAddress)
+	assert.Equal(t, big.NewInt(30), tok.Allowance(alice, bob))
+
+	// infinite approvals are never spent
+	assert.NoError(t, tok.Approve(alice, bob, math.MaxBig256))
+	assert.NoError(t, tok.TransferFrom(bob, alice, bob, big.NewInt(10)))
+	assert.Equal(t, math.MaxBig256, tok.Allowance(alice, bob))
+
+	assert.NoError(t, tok.Burn(bob, big.NewInt(60)))
+	assert.Equal(t, big.NewInt(40), tok.TotalSupply())
+	assert.ErrorIs(t, tok.Burn(bob, big.NewInt(1)), ErrInsufficientBalance)
+	assert.ErrorIs(t, tok.Mint(alice, math.MaxBig256), ethtypes.ErrOverflow)
+
+	tok = Get(tf.Namespace("token"))
+	assert.Equal(t, "Gold", tok.Name())
+	assert.Equal(t, "GLD", tok.Symbol())
+	assert.Equal(t, uint8(18), tok.Decimals())
+	assert.Equal(t, big.NewInt(40), tok.BalanceOf(alice))
+
+	logs := statedb.Logs()
+	// mint, transfer, approve, 2 transferFrom, approve, burn
+	assert.Len(t, logs, 7)
+	assert.Equal(t, addr, logs[0].Address)
+	assert.Equal(t, []common.Hash{TransferTopic, {}, alice.Hash()}, logs[0].Topics)
+	assert.Equal(t, common.BigToHash(big.NewInt(100)).Bytes(), logs[0].Data)
+	assert.Equal(t, ApprovalTopic, logs[2].Topics[0])
+}
+
+func TestHandlers(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := ethtypes.NewTypeFactory(statedb, addr)
+	New(tf.Namespace("gold"), "Gold", "GLD", 18).Mint(alice, big.NewInt(100))
+
+	// the ledger is opened part by part
+	meter := ethtypes.NewStorageMeter(statedb)
+	mtf, _ := ethtypes.NewTypeFactory(meter, addr)
+	Get(mtf.Namespace("gold"))
+	assert.Zero(t, meter.Reads())
+
+	c, err := precompile.New(ERC20ABI, addr, Handlers("gold"))
+	assert.NoError(t, err)
+	parsed := c.ABI()
+
+	input, _ := parsed.Pack("transfer", bob, big.NewInt(40))
+	p := c.Bind(statedb, alice)
+	assert.NotZero(t, p.RequiredGas(input))
+	_, err = p.Run(input)
+	assert.NoError(t, err)
+
+	input, _ = parsed.Pack("balanceOf", bob)
+	ret, err := c.Bind(statedb, bob).Run(input)
+	assert.NoError(t, err)
+	outs, _ := parsed.Unpack("balanceOf", ret)
+	assert.Equal(t, big.NewInt(40), outs[0])
+
+	// the root of the contract holds no ledger
+	assert.Zero(t, Get(tf).BalanceOf(bob).Sign())
+
+	input, _ = parsed.Pack("transfer", alice, big.NewInt(41))
+	ret, err = c.Bind(statedb, bob).Run(input)
+	assert.Equal(t, vm.ErrExecutionReverted, err)
+	reason, _ := abi.UnpackRevert(ret)
+	assert.Equal(t, ErrInsufficientBalance.Error(), reason)
+}
@@ -1,7 +1,9 @@
 package ethtypes
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -10,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -228,7 +231,7 @@ func TestSlice(t *testing.T) {
 	fmt.Println(ArrayToStr(slice1))
 	// Output: ["Hello", ",", "world", "!"]
 	slice2 := typs.NewStringSlice("ExampeStringSlice2", 2, 3, []string{"World", "!?"})
-	slice1.CopyFrom(slice2, 2, 0, slice2.Len())
+	assert.NoError(t, slice1.CopyFrom(slice2, 2, 0, slice2.Len()))
 	fmt.Println(ArrayToStr(slice1))
 
 	// Output: ["Hello", ",", "World", "!?"]
@@ -312,3 +315,612 @@ func TestIterableMap(t *testing.T) {
 	personMap1.Del("1")
 	fmt.Println("map: ", IterableMapToStr(personMap1))
 }
+
+func TestStorageMeter(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	// length and chunk of the value and of its descriptor
+	v := tf.NewInt("counter", 1)
+	assert.Equal(t, uint64(4), meter.Writes())
+	assert.Equal(t, 4*params.SstoreSetGasEIP2200, meter.GasUsed())
+
+	meter.Reset()
+	var val int
+	v.Get(&val)
+	// length and chunk, then length again for IsAssigned
+	assert.Equal(t, uint64(3), meter.Reads())
+	assert.Equal(t, 3*params.SloadGasEIP2200, meter.GasUsed())
+
+	meter.Reset()
+	v.Set(2)
+	assert.Equal(t, params.SloadGasEIP2200+params.SstoreResetGasEIP2200, meter.GasUsed())
+}
+
+func TestSliceGrowth(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	slice := tf.NewSlice("growth", 0, 1, IntType)
+	for i := 0; i < 100; i++ {
+		meter.Reset()
+		slice.Append(i)
+		// the element, the length and at most the capacity,
+		// each taking a length and a chunk slot
+		assert.LessOrEqual(t, meter.Writes(), uint64(6))
+	}
+	assert.Equal(t, 100, slice.Len())
+	assert.Equal(t, 128, slice.Cap())
+
+	for i := 0; i < slice.Len(); i++ {
+		var val int
+		slice.Get(i, &val)
+		assert.Equal(t, i, val)
+	}
+
+	// appending more than twice the capacity at once
+	slice = tf.NewSlice("bulk", 2, 3, IntType)
+	slice.Append(1, 2, 3, 4, 5)
+	assert.Equal(t, 7, slice.Len())
+	assert.Equal(t, 7, slice.Cap())
+}
+
+func TestSliceShrink(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	slice := tf.NewSlice("shrink", 0, 0, StringType).(*BasicSlice)
+	slice.Append("a", "b", "c", "d", "e", "f")
+
+	var val string
+	slice.Pop(&val)
+	assert.Equal(t, "f", val)
+	assert.False(t, slice.arr.getElem(5).IsAssigned())
+
+	slice.Truncate(2)
+	assert.Equal(t, 2, slice.Len())
+	assert.Equal(t, []interface{}{"a", "b"}, GetArrayElems(slice))
+	for i := 2; i < 5; i++ {
+		assert.False(t, slice.arr.getElem(i).IsAssigned())
+	}
+	assert.Panics(t, func() { slice.Truncate(3) })
+
+	slice.ShrinkToFit()
+	assert.Equal(t, 2, slice.Cap())
+
+	slice.Clear()
+	assert.Equal(t, 0, slice.Len())
+	assert.False(t, slice.arr.getElem(0).IsAssigned())
+}
+
+func TestContractStateDelete(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	cs := NewContractState(state, addr)
+
+	hash := common.HexToHash("1")
+	cs.Write(hash, bytes.Repeat([]byte{1}, 70))
+	assert.NotEqual(t, common.Hash{}, state.GetState(addr, chunkSlot(hash, 2)))
+	cs.Delete(hash)
+	assert.False(t, cs.Exists(hash))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, common.Hash{}, state.GetState(addr, chunkSlot(hash, i)))
+	}
+}
+
+func TestSliceBulk(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	slice := tf.NewSlice("bulk", 0, 0, IntType)
+	slice.Append(1, 2, 3)
+
+	slice.Insert(1, 7, 8)
+	assert.Equal(t, []interface{}{1, 7, 8, 2, 3}, GetArrayElems(slice))
+	slice.Insert(5, 9)
+	assert.Equal(t, []interface{}{1, 7, 8, 2, 3, 9}, GetArrayElems(slice))
+	assert.Panics(t, func() { slice.Insert(7, 0) })
+
+	var vals []int
+	slice.GetRange(1, 4, &vals)
+	assert.Equal(t, []int{7, 8, 2}, vals)
+	assert.Panics(t, func() { slice.GetRange(4, 7, &vals) })
+
+	slice.SetRange(4, []int{4, 5})
+	assert.Equal(t, []interface{}{1, 7, 8, 2, 4, 5}, GetArrayElems(slice))
+	assert.Panics(t, func() { slice.SetRange(5, []int{0, 0}) })
+
+	slice.Swap(0, 5)
+	assert.Equal(t, []interface{}{5, 7, 8, 2, 4, 1}, GetArrayElems(slice))
+
+	slice.Reverse()
+	assert.Equal(t, []interface{}{1, 4, 2, 8, 7, 5}, GetArrayElems(slice))
+
+	// Fill only covers the length, not the spare capacity.
+	var last int
+	slice.Pop(&last)
+	slice.Fill(6)
+	assert.Equal(t, []interface{}{6, 6, 6, 6, 6}, GetArrayElems(slice))
+	assert.False(t, slice.(*BasicSlice).arr.getElem(5).IsAssigned())
+
+	// Elements already equal to the fill value are not written.
+	meter := NewStorageMeter(state)
+	mtf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+	mtf.GetSlice("bulk", 0, 0, IntType).Fill(6)
+	assert.Equal(t, uint64(0), meter.Writes())
+}
+
+func TestArrayCopyFrom(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	arr := tf.NewArray("copy", 8, IntType)
+	arr.SetRange(0, []int{0, 1, 2, 3, 4, 5, 6, 7})
+
+	// overlapping copies in both directions
+	assert.NoError(t, arr.CopyFrom(arr, 2, 0, 5))
+	assert.Equal(t, []interface{}{0, 1, 0, 1, 2, 3, 4, 7}, GetArrayElems(arr))
+	assert.NoError(t, arr.CopyFrom(arr, 0, 2, 7))
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 3, 4, 7}, GetArrayElems(arr))
+
+	assert.ErrorIs(t, arr.CopyFrom(arr, 0, -1, 2), ErrIndexOutOfRange)
+	assert.ErrorIs(t, arr.CopyFrom(arr, 0, 3, 2), ErrIndexOutOfRange)
+	assert.ErrorIs(t, arr.CopyFrom(arr, 0, 5, 9), ErrIndexOutOfRange)
+	assert.ErrorIs(t, arr.CopyFrom(arr, 6, 0, 3), ErrIndexOutOfRange)
+	assert.ErrorIs(t, arr.CopyFrom(tf.NewArray("strs", 1, StringType), 0, 0, 1), ErrKindMismatch)
+
+	// the destination of a slice is bounded by its length
+	slice := tf.NewSlice("copySlice", 2, 8, IntType)
+	assert.ErrorIs(t, slice.CopyFrom(arr, 0, 0, 3), ErrIndexOutOfRange)
+	assert.NoError(t, slice.CopyFrom(arr, 0, 6, 8))
+	assert.Equal(t, []interface{}{4, 7}, GetArrayElems(slice))
+}
+
+func TestSliceSort(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	slice := tf.NewSlice("levels", 0, 0, IntType)
+	slice.Append(5, 1, 3, 4, 2)
+	assert.False(t, slice.IsSorted(nil))
+
+	// only the 3 elements that moved are written, with 2 slots each
+	meter := NewStorageMeter(state)
+	mtf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+	mtf.GetSlice("levels", 0, 0, IntType).Sort(nil)
+	assert.Equal(t, uint64(6), meter.Writes())
+	assert.Equal(t, []interface{}{1, 2, 3, 4, 5}, GetArrayElems(slice))
+	assert.True(t, slice.IsSorted(nil))
+
+	assert.Equal(t, 2, slice.SearchSorted(3, nil))
+	assert.Equal(t, 5, slice.SearchSorted(9, nil))
+
+	assert.Equal(t, 3, slice.InsertSorted(3, nil))
+	assert.Equal(t, 0, slice.InsertSorted(0, nil))
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 3, 4, 5}, GetArrayElems(slice))
+
+	assert.True(t, slice.RemoveSorted(3, nil))
+	assert.False(t, slice.RemoveSorted(7, nil))
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5}, GetArrayElems(slice))
+
+	desc := func(a, b interface{}) bool { return a.(int) > b.(int) }
+	slice.Sort(desc)
+	assert.Equal(t, []interface{}{5, 4, 3, 2, 1, 0}, GetArrayElems(slice))
+	assert.Equal(t, 4, slice.SearchSorted(1, desc))
+
+	amounts := tf.NewSlice("amounts", 0, 0, BigIntType)
+	amounts.Append(big.NewInt(300), big.NewInt(-1), big.NewInt(20))
+	amounts.Sort(nil)
+	var amount big.Int
+	amounts.Get(2, &amount)
+	assert.Equal(t, int64(300), amount.Int64())
+	assert.Equal(t, 1, amounts.SearchSorted(big.NewInt(5), nil))
+}
+
+func TestIterator(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	collect := func(it Iterator) (keys, vals []interface{}) {
+		for it.Next() {
+			keys = append(keys, it.Key())
+			vals = append(vals, it.Value())
+		}
+		assert.NoError(t, it.Err())
+		return
+	}
+
+	slice := tf.NewSlice("iter", 0, 0, StringType)
+	slice.Append("a", "b", "c", "d", "e")
+
+	keys, vals := collect(slice.Iter())
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, keys)
+	assert.Equal(t, []interface{}{"a", "b", "c", "d", "e"}, vals)
+
+	keys, vals = collect(slice.ReverseIter())
+	assert.Equal(t, []interface{}{4, 3, 2, 1, 0}, keys)
+	assert.Equal(t, []interface{}{"e", "d", "c", "b", "a"}, vals)
+
+	_, vals = collect(slice.Page(1, 2))
+	assert.Equal(t, []interface{}{"b", "c"}, vals)
+	_, vals = collect(slice.Page(4, 10))
+	assert.Equal(t, []interface{}{"e"}, vals)
+	_, vals = collect(slice.Page(5, 10))
+	assert.Nil(t, vals)
+
+	it := slice.Page(-1, 2)
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), ErrIndexOutOfRange)
+
+	var visited []int
+	slice.Range(func(index int, val interface{}) bool {
+		visited = append(visited, index)
+		return index < 2
+	})
+	assert.Equal(t, []int{0, 1, 2}, visited)
+
+	it = slice.Iter()
+	assert.True(t, it.Next())
+	slice.Append("f")
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), ErrConcurrentModification)
+
+	m := tf.NewIterableMap("iterMap", StringType, IntType)
+	m.Set("x", 1)
+	m.Set("y", 2)
+	m.Set("z", 3)
+
+	keys, vals = collect(m.Iter())
+	assert.Equal(t, []interface{}{"x", "y", "z"}, keys)
+	assert.Equal(t, []interface{}{1, 2, 3}, vals)
+	keys, _ = collect(m.ReverseIter())
+	assert.Equal(t, []interface{}{"z", "y", "x"}, keys)
+	keys, vals = collect(m.Page(1, 1))
+	assert.Equal(t, []interface{}{"y"}, keys)
+	assert.Equal(t, []interface{}{2}, vals)
+}
+
+func TestCommitted(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	slice := tf.NewCommittedSlice("validators", 0, 0, StringType)
+	empty := slice.Root()
+	slice.Append("a", "b", "c")
+	assert.NotEqual(t, empty, slice.Root())
+
+	proof, err := slice.Proof(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`"b"`), proof.Value)
+	assert.True(t, VerifyProof(slice.Root(), proof))
+	proof.Value = []byte(`"x"`)
+	assert.False(t, VerifyProof(slice.Root(), proof))
+	_, err = slice.Proof(3)
+	assert.ErrorIs(t, err, ErrIndexOutOfRange)
+
+	// the root only depends on the elements
+	other := tf.NewCommittedSlice("other", 0, 0, StringType)
+	other.Append("a", "x", "b", "c", "d")
+	other.Del(1)
+	var last string
+	other.Pop(&last)
+	assert.Equal(t, slice.Root(), other.Root())
+	other.Clear()
+	assert.Equal(t, empty, other.Root())
+
+	_, err = tf.NewSlice("plain", 0, 0, StringType).(*BasicSlice).Proof(0)
+	assert.ErrorIs(t, err, ErrNotCommitted)
+
+	m := tf.NewCommittedIterableMap("balances", StringType, IntType)
+	m.Set("alice", 1)
+	m.Set("bob", 2)
+	m.Set("carol", 3)
+	m.Set("bob", 4)
+
+	proof, err = m.Proof("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), proof.Index)
+	assert.Equal(t, []byte("4"), proof.Value)
+	assert.True(t, VerifyProof(m.Root(), proof))
+
+	m.Del("alice")
+	proof, err = m.Proof("carol")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), proof.Index)
+	assert.True(t, VerifyProof(m.Root(), proof))
+	_, err = m.Proof("alice")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	fresh := tf.NewCommittedIterableMap("fresh", StringType, IntType)
+	fresh.Set("bob", 4)
+	fresh.Set("carol", 3)
+	assert.Equal(t, fresh.Root(), m.Root())
+	assert.Equal(t, m.Root(), tf.GetCommittedIterableMap("balances", StringType, IntType).Root())
+
+	// plain handles would leave the root stale
+	assert.Panics(t, func() { tf.GetSlice("validators", 0, 0, StringType) })
+	assert.Panics(t, func() { tf.GetIterableMap("balances", StringType, IntType) })
+	err = tf.MigrateMap("balances", StringType, IntType, Int64Type, []interface{}{"bob"}, func(old interface{}) (interface{}, error) {
+		return int64(old.(int)), nil
+	})
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+}
+
+func TestReadOnlyTypeFactory(t *testing.T) {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	st, _ := state.New(common.Hash{}, db, nil)
+	addr := common.HexToAddress("123")
+	tf, _ := NewTypeFactory(st, addr)
+	tf.NewString("name", "old")
+	tf.NewSlice("list", 0, 0, IntType).Append(1, 2)
+	root, _ := st.Commit(false)
+
+	tf.GetVariable("name", StringType).Set("new")
+
+	ro, err := OpenReadOnlyTypeFactory(root, db, addr)
+	assert.NoError(t, err)
+	var name string
+	ro.GetVariable("name", StringType).Get(&name)
+	assert.Equal(t, "old", name)
+
+	list := ro.GetSlice("list", 0, 0, IntType)
+	assert.Equal(t, []interface{}{1, 2}, GetArrayElems(list))
+	assert.PanicsWithValue(t, ErrReadOnly, func() { list.Append(3) })
+	assert.PanicsWithValue(t, ErrReadOnly, func() { list.Set(0, 3) })
+	assert.PanicsWithValue(t, ErrReadOnly, func() { list.Del(0) })
+	assert.PanicsWithValue(t, ErrReadOnly, func() { ro.GetVariable("name", StringType).Del() })
+	assert.Equal(t, []interface{}{1, 2}, GetArrayElems(list))
+}
+
+func TestNamespace(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	staking := tf.Namespace("staking")
+	gov := tf.Namespace("gov")
+	tf.NewString("owner", "root")
+	staking.NewString("owner", "staking")
+	gov.NewString("owner", "gov")
+	staking.Namespace("gov").NewString("owner", "nested")
+
+	get := func(tf *TypeFactory) string {
+		var owner string
+		tf.GetVariable("owner", StringType).Get(&owner)
+		return owner
+	}
+	assert.Equal(t, "root", get(tf))
+	assert.Equal(t, "staking", get(staking))
+	assert.Equal(t, "gov", get(gov))
+	assert.Equal(t, "nested", get(tf.Namespace("staking").Namespace("gov")))
+	assert.Equal(t, "", get(tf.Namespace("other")))
+
+	staking.NewSlice("validators", 0, 0, StringType).Append("a")
+	assert.Equal(t, 0, tf.GetSlice("validators", 0, 0, StringType).Len())
+	assert.Equal(t, 1, staking.GetSlice("validators", 0, 0, StringType).Len())
+}
+
+func TestStrictTypes(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"), WithStrictTypes())
+
+	typeError := func(fn func()) (err *TypeError) {
+		defer func() {
+			err, _ = recover().(*TypeError)
+		}()
+		fn()
+		return nil
+	}
+
+	v := tf.NewVariable("total", int64(0))
+	v.Set(int8(-3))
+	var total int64
+	v.Get(&total)
+	assert.Equal(t, int64(-3), total)
+	v.Set(uint32(7))
+	v.Get(&total)
+	assert.Equal(t, int64(7), total)
+
+	err := typeError(func() { v.Set(uint64(1)) })
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+	assert.Equal(t, "set total: expect type int64, actual type uint64", err.Error())
+	err = typeError(func() {
+		var small int32
+		v.Get(&small)
+	})
+	assert.Equal(t, "get", err.Op)
+
+	data := tf.NewVariable("data", []byte{1})
+	assert.NotNil(t, typeError(func() { data.Set([]string{"a"}) }))
+	assert.NotNil(t, typeError(func() { tf.NewVariable("person", Person{}).Set(Location{}) }))
+
+	arr := tf.NewArray("amounts", 2, Float64Type)
+	arr.Set(0, float32(1.5))
+	assert.NotNil(t, typeError(func() { arr.Set(1, 2) }))
+
+	m := tf.NewMap("balances", Int64Type, StringType)
+	m.Set(int8(1), "a")
+	var s string
+	assert.True(t, m.Get(int64(1), &s))
+	assert.Equal(t, "a", s)
+	assert.NotNil(t, typeError(func() { m.Contains("1") }))
+
+	im := tf.NewIterableMap("voters", Int64Type, StringType)
+	im.Set(int8(1), "a")
+	assert.True(t, im.Contains(int16(1)))
+	im.Del(int8(1))
+	assert.False(t, im.Contains(int64(1)))
+	assert.Equal(t, 0, im.Len())
+
+	// values stored as another type fail loudly
+	tf.NewVariable("name", "alice")
+	assert.Panics(t, func() {
+		var n int64
+		tf.GetVariable("name", Int64Type).Get(&n)
+	})
+
+	assert.True(t, Widens(Uint8Type, IntType))
+	assert.False(t, Widens(Uint64Type, Int64Type))
+	assert.False(t, Widens(IntType, Float64Type))
+}
+
+func TestOpen(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	tf, _ := NewTypeFactory(state, common.HexToAddress("123"))
+
+	RegisterType(reflect.TypeOf(Person{}))
+	assert.Equal(t, "github.com/TheStarBoys/ethtypes.Person", TypeName(reflect.TypeOf(Person{})))
+	// another Person of the same package cannot take its name
+	assert.Panics(t, func() {
+		type Person struct{ ID int }
+		RegisterType(reflect.TypeOf(Person{}))
+	})
+	tf.NewSlice("people", 0, 0, reflect.TypeOf(Person{})).Append(Person{Name: "alice"})
+	tf.NewCommittedIterableMap("balances", AddressType, BigIntType).Set(common.HexToAddress("1"), big.NewInt(5))
+	tf.NewVariable("owner", common.HexToAddress("2"))
+
+	c, err := tf.Open("people")
+	assert.NoError(t, err)
+	people := c.(Slice)
+	assert.Equal(t, reflect.TypeOf(Person{}), people.ElemType())
+	var p Person
+	people.Get(0, &p)
+	assert.Equal(t, "alice", p.Name)
+
+	c, err = tf.Open("balances")
+	assert.NoError(t, err)
+	balances := c.(CommittedIterableMap)
+	var balance big.Int
+	balances.Get(common.HexToAddress("1"), &balance)
+	assert.Equal(t, int64(5), balance.Int64())
+	assert.NotEqual(t, common.Hash{}, balances.Root())
+
+	c, err = tf.Open("owner")
+	assert.NoError(t, err)
+	assert.Equal(t, AddressType, c.(StateVariable).Type())
+
+	_, err = tf.Open("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// mismatched Get* fail loudly
+	assert.Panics(t, func() { tf.GetSlice("people", 0, 0, StringType) })
+	assert.Panics(t, func() { tf.GetArray("people", 0, reflect.TypeOf(Person{})) })
+	assert.Panics(t, func() { tf.GetIterableMap("balances", AddressType, BigIntType) })
+	assert.Panics(t, func() { tf.GetMap("balances", AddressType, BigIntType) })
+	assert.Panics(t, func() { tf.GetVariable("owner", StringType) })
+	assert.NotPanics(t, func() { tf.GetCommittedIterableMap("balances", AddressType, BigIntType) })
+
+	// narrower stored types can be read as wider ones
+	tf.NewArray("small", 1, Int8Type)
+	assert.NotPanics(t, func() { tf.GetArray("small", 0, Int64Type) })
+}
+
+func TestFixedBytes(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	meter := NewStorageMeter(state)
+	tf, _ := NewTypeFactory(meter, common.HexToAddress("123"))
+
+	h := common.HexToHash("0x0102")
+	v := tf.NewVariable("root", h)
+
+	// a single slot without a length header
+	meter.Reset()
+	v.Set(common.HexToHash("0x0304"))
+	assert.Equal(t, uint64(1), meter.Writes())
+	assert.Equal(t, params.SstoreResetGasEIP2200, meter.GasUsed())
+
+	var got common.Hash
+	assert.True(t, v.Get(&got))
+	assert.Equal(t, common.HexToHash("0x0304"), got)
+	v.Del()
+	assert.False(t, v.IsAssigned())
+
+	// bytesN are left aligned like in Solidity
+	sel := tf.NewVariable("selector", [4]byte{0xa9, 0x05, 0x9c, 0xbb})
+	assert.Equal(t, common.Hash{0xa9, 0x05, 0x9c, 0xbb}, state.GetState(common.HexToAddress("123"), sel.Addr()))
+
+	arr := tf.NewArray("hashes", 2, HashType)
+	arr.Set(1, h)
+	arr.Get(1, &got)
+	assert.Equal(t, h, got)
+	arr.Swap(0, 1)
+	arr.Get(0, &got)
+	assert.Equal(t, h, got)
+
+	m := tf.NewIterableMap("selectors", Bytes4Type, Bytes32Type)
+	m.Set([4]byte{1}, [32]byte{2})
+	var val [32]byte
+	assert.True(t, m.Get([4]byte{1}, &val))
+	assert.Equal(t, [32]byte{2}, val)
+	assert.False(t, m.Get([4]byte{2}, &val))
+
+	typ, err := ParseType(TypeName(Bytes4Type))
+	assert.NoError(t, err)
+	assert.Equal(t, Bytes4Type, typ)
+
+	// hashes stored as JSON by earlier versions are still read,
+	// until a write replaces them
+	legacy := tf.GetVariable("legacy", HashType)
+	tf.state.Write(legacy.Addr(), []byte(`"0x0000000000000000000000000000000000000000000000000000000000000506"`))
+	assert.True(t, legacy.IsAssigned())
+	assert.True(t, legacy.Get(&got))
+	assert.Equal(t, common.HexToHash("0x0506"), got)
+	legacy.Set(common.HexToHash("0x07"))
+	legacy.Get(&got)
+	assert.Equal(t, common.HexToHash("0x07"), got)
+	legacy.Del()
+	assert.False(t, legacy.IsAssigned())
+	assert.False(t, tf.state.Exists(legacy.Addr()))
+
+	// a zero hash replaces a legacy value
+	tf.state.Write(legacy.Addr(), []byte(`"0x0000000000000000000000000000000000000000000000000000000000000506"`))
+	legacy.Set(common.Hash{})
+	assert.True(t, legacy.Get(&got))
+	assert.Equal(t, common.Hash{}, got)
+	assert.False(t, tf.state.Exists(legacy.Addr()))
+}
+
+func TestFixedBytesZero(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	addr := common.HexToAddress("123")
+	tf, _ := NewTypeFactory(state, addr)
+
+	// zero is assigned, unlike a missing value
+	v := tf.NewVariable("root", common.Hash{})
+	assert.True(t, v.IsAssigned())
+	var got common.Hash
+	assert.True(t, v.Get(&got))
+	v.Set(common.HexToHash("0x01"))
+	v.Set(common.Hash{})
+	assert.True(t, v.IsAssigned())
+	v.Del()
+	assert.False(t, v.IsAssigned())
+	assert.Equal(t, common.Hash{}, state.GetState(addr, assignedSlot(v.Addr())))
+
+	m := tf.NewIterableMap("roots", AddressType, HashType)
+	for i := 0; i < 3; i++ {
+		m.Set(addr, common.Hash{})
+	}
+	assert.Equal(t, 1, m.Len())
+	assert.True(t, m.Contains(addr))
+	assert.True(t, m.Get(addr, &got))
+	assert.Equal(t, common.Hash{}, got)
+	m.Del(addr)
+	assert.Equal(t, 0, m.Len())
+	assert.False(t, m.Contains(addr))
+}